@@ -0,0 +1,421 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+This file contains the controller used to track the capacity available
+to the hostpath StorageClasses on each node.
+
+At every poll interval, the space available to the filesystem of every
+distinct BasePath of the hostpath StorageClasses is published as a
+CSIStorageCapacity object per StorageClass and node, in the namespace of
+the provisioner. The space is measured by a helper pod on the node. The
+measured space is cached, and measured again only if the hostpath
+volumes of the node have changed or the cached space is older than
+capacityMaxAge, so that the helper pods are not launched on every node
+at every poll interval.
+
+The Kubernetes scheduler reads CSIStorageCapacity objects only for CSI
+drivers with storageCapacity enabled, which openebs.io/local is not. The
+objects are read by Provision, which reschedules a volume if the
+published capacity of the selected node is less than the requested
+storage.
+*/
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// capacityWorkers is the number of nodes on which the capacity is
+	// measured in parallel.
+	capacityWorkers = 8
+
+	// capacityMaxAge is the age after which the capacity of a BasePath
+	// is measured again, even if the hostpath volumes of the node have
+	// not changed. It bounds the staleness of the capacity used by
+	// volumes without quota and by other files in the BasePath.
+	capacityMaxAge = 30 * time.Minute
+)
+
+// CapacityController publishes the capacity available to the hostpath
+// StorageClasses on each node.
+type CapacityController struct {
+	provisioner *Provisioner
+	interval    time.Duration
+	cache       *capacityCache
+}
+
+// capacityCache has the space measured on the BasePaths of each node.
+type capacityCache struct {
+	sync.Mutex
+	// entries has the measured space by node name and BasePath.
+	entries map[string]capacityCacheEntry
+}
+
+type capacityCacheEntry struct {
+	available int64
+	// volumes identifies the hostpath volumes of the node at the
+	// time the space was measured.
+	volumes  string
+	measured time.Time
+}
+
+func newCapacityCache() *capacityCache {
+	return &capacityCache{entries: map[string]capacityCacheEntry{}}
+}
+
+// get returns the cached space of the BasePath on the node, if the
+// hostpath volumes of the node have not changed and the space is not
+// older than capacityMaxAge.
+func (c *capacityCache) get(node, basePath, volumes string, now time.Time) (int64, bool) {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[node+":"+basePath]
+	if !ok || entry.volumes != volumes || now.Sub(entry.measured) > capacityMaxAge {
+		return 0, false
+	}
+	return entry.available, true
+}
+
+// set caches the space measured on the BasePath of the node.
+func (c *capacityCache) set(node, basePath, volumes string, available int64, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[node+":"+basePath] = capacityCacheEntry{
+		available: available,
+		volumes:   volumes,
+		measured:  now,
+	}
+}
+
+// prune removes the space measured before capacityMaxAge, e.g. on the
+// nodes which no longer exist.
+func (c *capacityCache) prune(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	for key, entry := range c.entries {
+		if now.Sub(entry.measured) > capacityMaxAge {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// NewCapacityController returns a CapacityController which measures
+// the capacity at the given interval.
+func NewCapacityController(p *Provisioner, interval time.Duration) *CapacityController {
+	return &CapacityController{
+		provisioner: p,
+		interval:    interval,
+		cache:       newCapacityCache(),
+	}
+}
+
+// Run publishes the capacity at every interval till the context is
+// cancelled.
+func (cc *CapacityController) Run(ctx context.Context) {
+	klog.Infof("Starting capacity controller with poll interval %v", cc.interval)
+	defer klog.Info("Shutting down capacity controller")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := cc.sync(ctx); err != nil {
+			klog.Errorf("Failed to publish capacity: %v", err)
+		}
+	}, cc.interval)
+}
+
+// sync measures and publishes the capacity of every hostpath
+// StorageClass on every schedulable node, and deletes the capacity
+// objects of the StorageClasses and nodes which no longer exist.
+func (cc *CapacityController) sync(ctx context.Context) error {
+	p := cc.provisioner
+	scList, err := p.kubeClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list storageclasses")
+	}
+	nodeList, err := p.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+	nodes := []*v1.Node{}
+	for i := range nodeList.Items {
+		if !nodeList.Items[i].Spec.Unschedulable {
+			nodes = append(nodes, &nodeList.Items[i])
+		}
+	}
+
+	// volumeConfigs maps the hostpath StorageClasses to their config
+	volumeConfigs := map[string]*VolumeConfig{}
+	for _, sc := range scList.Items {
+		if sc.Provisioner != provisionerName {
+			continue
+		}
+		volumeConfig, err := cc.getHostPathConfig(ctx, sc.Name)
+		if err != nil {
+			klog.Warningf("Skipping capacity of storageclass %v: %v", sc.Name, err)
+			continue
+		}
+		if volumeConfig != nil {
+			volumeConfigs[sc.Name] = volumeConfig
+		}
+	}
+
+	nodeVolumes, err := cc.getNodeVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	cc.cache.prune(time.Now())
+
+	published := map[string]bool{}
+	var mutex sync.Mutex
+	workqueue.ParallelizeUntil(ctx, capacityWorkers, len(nodes), func(i int) {
+		node := nodes[i]
+		volumes := nodeVolumes[GetNodeHostname(node)]
+		// available caches the capacity of the BasePaths shared
+		// by more than one StorageClass
+		available := map[string]int64{}
+		for scName, volumeConfig := range volumeConfigs {
+			paths, err := volumeConfig.getNodeBasePaths(node)
+			if err != nil {
+				klog.Errorf("Failed to get base path of storageclass %v on node %v: %v", scName, node.Name, err)
+				continue
+			}
+			// A volume is placed on one of the BasePaths, so the
+			// largest available capacity is published.
+			capacity, measured := int64(0), false
+			for _, basePath := range paths {
+				if _, ok := available[basePath]; !ok {
+					free, ok := cc.cache.get(node.Name, basePath, volumes, time.Now())
+					if !ok {
+						err := p.trackOperation("capacity of "+basePath+" on "+node.Name, helperStallTimeout, func() (err error) {
+							_, free, err = p.getFilesystemUsage(ctx, node, shortHash(node.Name+":"+basePath), basePath)
+							return err
+						})
+						if err != nil {
+							klog.Errorf("Failed to get capacity of %v on node %v: %v", basePath, node.Name, err)
+							continue
+						}
+						cc.cache.set(node.Name, basePath, volumes, free, time.Now())
+					}
+					available[basePath] = free
+				}
+				if !measured || available[basePath] > capacity {
+					capacity, measured = available[basePath], true
+				}
+			}
+			if !measured {
+				continue
+			}
+			name := capacityObjectName(scName, node.Name)
+			if err := p.publishCapacity(ctx, name, scName, node, capacity); err != nil {
+				klog.Errorf("Failed to publish capacity of storageclass %v on node %v: %v", scName, node.Name, err)
+				continue
+			}
+			mutex.Lock()
+			published[name] = true
+			mutex.Unlock()
+		}
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	capacityList, err := p.kubeClient.StorageV1().CSIStorageCapacities(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: string(mconfig.CASTypeKey) + "=local-hostpath",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list csistoragecapacities")
+	}
+	for _, capacity := range capacityList.Items {
+		if published[capacity.Name] {
+			continue
+		}
+		// Retain the capacity of the nodes on which the capacity
+		// could not be measured in this sync.
+		if _, ok := volumeConfigs[capacity.StorageClassName]; ok && nodeExists(nodes, capacity.NodeTopology) {
+			continue
+		}
+		err := p.kubeClient.StorageV1().CSIStorageCapacities(p.namespace).Delete(ctx, capacity.Name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			klog.Errorf("Failed to delete csistoragecapacity %v: %v", capacity.Name, err)
+		}
+	}
+	return nil
+}
+
+// getNodeVolumes returns a hash of the names and sizes of the hostpath
+// volumes on each node, by hostname. The hash changes when a volume of
+// the node is created, deleted or expanded.
+func (cc *CapacityController) getNodeVolumes(ctx context.Context) (map[string]string, error) {
+	pvList, err := cc.provisioner.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
+		LabelSelector: string(mconfig.CASTypeKey) + "=local-hostpath",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list persistentvolumes")
+	}
+
+	volumes := map[string][]string{}
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		hostname := persistentvolume.NewForAPIObject(pv).GetAffinitedNodeLabels()[k8sNodeLabelKeyHostname]
+		size := pv.Spec.Capacity[v1.ResourceStorage]
+		volumes[hostname] = append(volumes[hostname], pv.Name+"="+size.String())
+	}
+	nodeVolumes := map[string]string{}
+	for hostname, names := range volumes {
+		sort.Strings(names)
+		nodeVolumes[hostname] = shortHash(strings.Join(names, ","))
+	}
+	return nodeVolumes, nil
+}
+
+// getHostPathConfig returns the config of the hostpath StorageClass.
+// No config is returned for the StorageClasses of other StorageTypes
+// and with an AbsolutePath.
+func (cc *CapacityController) getHostPathConfig(ctx context.Context, scName string) (*VolumeConfig, error) {
+	pvc := &v1.PersistentVolumeClaim{
+		Spec: v1.PersistentVolumeClaimSpec{StorageClassName: &scName},
+	}
+	volumeConfig, err := cc.provisioner.getVolumeConfig(ctx, "", pvc)
+	if err != nil {
+		return nil, err
+	}
+	if volumeConfig.GetStorageType() != "hostpath" || volumeConfig.IsAbsolutePath() {
+		return nil, nil
+	}
+	return volumeConfig, nil
+}
+
+// getFilesystemUsage returns the space in bytes used and available in
+// the filesystem of the directory on the node. The name is used to
+// name the helper pod.
+func (p *Provisioner) getFilesystemUsage(ctx context.Context, node *v1.Node, name, path string) (int64, int64, error) {
+	hostname := GetNodeHostname(node)
+	if hostname == "" {
+		return 0, 0, errors.Errorf("node{%v} hostname is empty", node.Name)
+	}
+	podOpts := &HelperPodOptions{
+		name:               name,
+		path:               path,
+		nodeAffinityLabels: map[string]string{k8sNodeLabelKeyHostname: hostname},
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(node),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+	}
+	return p.createCapacityPod(ctx, podOpts)
+}
+
+// publishCapacity creates or updates the CSIStorageCapacity object with
+// the capacity available to the StorageClass on the node.
+func (p *Provisioner) publishCapacity(ctx context.Context, name, scName string, node *v1.Node, available int64) error {
+	capacityClient := p.kubeClient.StorageV1().CSIStorageCapacities(p.namespace)
+	nodeTopology := &metav1.LabelSelector{
+		MatchLabels: map[string]string{k8sNodeLabelKeyHostname: GetNodeHostname(node)},
+	}
+	quantity := resource.NewQuantity(available, resource.BinarySI)
+
+	capacity, err := capacityClient.Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		capacity = &storagev1.CSIStorageCapacity{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: p.namespace,
+				Labels: map[string]string{
+					string(mconfig.CASTypeKey): "local-hostpath",
+				},
+			},
+			NodeTopology:     nodeTopology,
+			StorageClassName: scName,
+			Capacity:         quantity,
+		}
+		_, err = capacityClient.Create(ctx, capacity, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	capacity.NodeTopology = nodeTopology
+	capacity.Capacity = quantity
+	_, err = capacityClient.Update(ctx, capacity, metav1.UpdateOptions{})
+	return err
+}
+
+// checkNodeCapacity returns an error with the ProvisioningReschedule
+// state, if the capacity published for the StorageClass on the node is
+// less than the requested storage. The volume is provisioned if the
+// capacity has not been published yet.
+func (p *Provisioner) checkNodeCapacity(ctx context.Context, scName string, node *v1.Node, size resource.Quantity) (pvController.ProvisioningState, error) {
+	name := capacityObjectName(scName, node.Name)
+	capacity, err := p.kubeClient.StorageV1().CSIStorageCapacities(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			klog.Warningf("Failed to get capacity of storageclass %v on node %v: %v", scName, node.Name, err)
+		}
+		return pvController.ProvisioningFinished, nil
+	}
+	if capacity.Capacity != nil && capacity.Capacity.Cmp(size) < 0 {
+		return pvController.ProvisioningReschedule, errors.Errorf(
+			"node %v has %v available for storageclass %v, requested %v",
+			node.Name, capacity.Capacity.String(), scName, size.String())
+	}
+	return pvController.ProvisioningFinished, nil
+}
+
+// capacityObjectName returns the name of the CSIStorageCapacity object
+// of the StorageClass on the node.
+func capacityObjectName(scName, nodeName string) string {
+	return "localpv-" + shortHash(scName+"/"+nodeName)
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// nodeExists returns true if any of the nodes matches the node topology
+// of a CSIStorageCapacity object.
+func nodeExists(nodes []*v1.Node, nodeTopology *metav1.LabelSelector) bool {
+	if nodeTopology == nil {
+		return false
+	}
+	for _, node := range nodes {
+		if GetNodeHostname(node) == nodeTopology.MatchLabels[k8sNodeLabelKeyHostname] {
+			return true
+		}
+	}
+	return false
+}
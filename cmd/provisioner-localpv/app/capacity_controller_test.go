@@ -0,0 +1,216 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func TestCheckNodeCapacity(t *testing.T) {
+	fakeCapacity := func(capacity string) *storagev1.CSIStorageCapacity {
+		quantity := resource.MustParse(capacity)
+		return &storagev1.CSIStorageCapacity{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      capacityObjectName("openebs-hostpath", "node-1"),
+				Namespace: "openebs",
+			},
+			StorageClassName: "openebs-hostpath",
+			Capacity:         &quantity,
+		}
+	}
+
+	testCases := map[string]struct {
+		capacity    *storagev1.CSIStorageCapacity
+		size        string
+		expectState pvController.ProvisioningState
+		expectErr   bool
+	}{
+		"enough capacity": {
+			capacity:    fakeCapacity("10Gi"),
+			size:        "5Gi",
+			expectState: pvController.ProvisioningFinished,
+		},
+		"not enough capacity": {
+			capacity:    fakeCapacity("1Gi"),
+			size:        "5Gi",
+			expectState: pvController.ProvisioningReschedule,
+			expectErr:   true,
+		},
+		"capacity not published": {
+			size:        "5Gi",
+			expectState: pvController.ProvisioningFinished,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			objs := []runtime.Object{}
+			if v.capacity != nil {
+				objs = append(objs, v.capacity)
+			}
+			p := &Provisioner{kubeClient: fake.NewSimpleClientset(objs...), namespace: "openebs"}
+
+			state, err := p.checkNodeCapacity(context.TODO(), "openebs-hostpath", fakeNode("node-1"), resource.MustParse(v.size))
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %t got %v", v.expectErr, err)
+			}
+			if state != v.expectState {
+				t.Errorf("expected state %v got %v", v.expectState, state)
+			}
+		})
+	}
+}
+
+func TestPublishCapacity(t *testing.T) {
+	p := &Provisioner{kubeClient: fake.NewSimpleClientset(), namespace: "openebs"}
+	name := capacityObjectName("openebs-hostpath", "node-1")
+
+	for _, available := range []int64{4 << 30, 2 << 30} {
+		if err := p.publishCapacity(context.TODO(), name, "openebs-hostpath", fakeNode("node-1"), available); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		capacity, err := p.kubeClient.StorageV1().CSIStorageCapacities("openebs").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected capacity %v to be published, got %v", name, err)
+		}
+		if capacity.Capacity.Value() != available {
+			t.Errorf("expected capacity %v got %v", available, capacity.Capacity.Value())
+		}
+		if capacity.StorageClassName != "openebs-hostpath" ||
+			capacity.NodeTopology.MatchLabels[k8sNodeLabelKeyHostname] != "node-1" {
+			t.Errorf("unexpected capacity %v", capacity)
+		}
+	}
+}
+
+func TestCapacityCache(t *testing.T) {
+	now := time.Now()
+	testCases := map[string]struct {
+		node        string
+		volumes     string
+		now         time.Time
+		expectFound bool
+	}{
+		"volumes not changed": {
+			node:        "node-1",
+			volumes:     "a",
+			now:         now.Add(time.Minute),
+			expectFound: true,
+		},
+		"volumes changed": {
+			node:    "node-1",
+			volumes: "b",
+			now:     now.Add(time.Minute),
+		},
+		"older than max age": {
+			node:    "node-1",
+			volumes: "a",
+			now:     now.Add(capacityMaxAge + time.Second),
+		},
+		"not measured": {
+			node:    "node-2",
+			volumes: "a",
+			now:     now,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			c := newCapacityCache()
+			c.set("node-1", "/var/openebs/local", "a", 1<<30, now)
+			available, found := c.get(v.node, "/var/openebs/local", v.volumes, v.now)
+			if found != v.expectFound {
+				t.Fatalf("expected found %v, but got %v", v.expectFound, found)
+			}
+			if found && available != 1<<30 {
+				t.Errorf("expected available %v, but got %v", 1<<30, available)
+			}
+		})
+	}
+}
+
+func TestGetNodeVolumes(t *testing.T) {
+	fakePV := func(name, node, size string) *v1.PersistentVolume {
+		pv, err := persistentvolume.NewBuilder().
+			WithName(name).
+			WithLabels(map[string]string{string(mconfig.CASTypeKey): "local-hostpath"}).
+			WithCapacityQty(resource.MustParse(size)).
+			WithLocalHostDirectory("/var/openebs/local/" + name).
+			WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: node}).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pv
+	}
+	getNodeVolumes := func(objects ...runtime.Object) map[string]string {
+		cc := NewCapacityController(&Provisioner{kubeClient: fake.NewSimpleClientset(objects...)}, time.Minute)
+		nodeVolumes, err := cc.getNodeVolumes(context.TODO())
+		if err != nil {
+			t.Fatalf("expected error to be nil, but got %v", err)
+		}
+		return nodeVolumes
+	}
+
+	before := getNodeVolumes(fakePV("pvc-1", "node-1", "1Gi"), fakePV("pvc-2", "node-2", "1Gi"))
+	testCases := map[string]struct {
+		objects       []runtime.Object
+		expectChanged map[string]bool
+	}{
+		"not changed": {
+			objects: []runtime.Object{fakePV("pvc-2", "node-2", "1Gi"), fakePV("pvc-1", "node-1", "1Gi")},
+		},
+		"volume created": {
+			objects:       []runtime.Object{fakePV("pvc-1", "node-1", "1Gi"), fakePV("pvc-2", "node-2", "1Gi"), fakePV("pvc-3", "node-1", "1Gi")},
+			expectChanged: map[string]bool{"node-1": true},
+		},
+		"volume deleted": {
+			objects:       []runtime.Object{fakePV("pvc-1", "node-1", "1Gi")},
+			expectChanged: map[string]bool{"node-2": true},
+		},
+		"volume expanded": {
+			objects:       []runtime.Object{fakePV("pvc-1", "node-1", "1Gi"), fakePV("pvc-2", "node-2", "2Gi")},
+			expectChanged: map[string]bool{"node-2": true},
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			after := getNodeVolumes(v.objects...)
+			for _, node := range []string{"node-1", "node-2"} {
+				if changed := before[node] != after[node]; changed != v.expectChanged[node] {
+					t.Errorf("expected volumes of %v changed %v, but got %v", node, v.expectChanged[node], changed)
+				}
+			}
+		})
+	}
+}
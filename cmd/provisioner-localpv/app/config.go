@@ -18,9 +18,14 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
 	"context"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
 	cast "github.com/openebs/maya/pkg/castemplate/v1alpha1"
@@ -28,6 +33,7 @@ import (
 	"github.com/openebs/maya/pkg/util"
 	errors "github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -39,8 +45,30 @@ const (
 
 	//KeyPVBasePath defines base directory for hostpath volumes
 	// can be configured via the StorageClass annotations.
+	// The value may refer to the name and labels of the node on which
+	// the volume is provisioned, using {{ .Node.Name }} and
+	// {{ index .Node.Labels "<label key>" }}.
+	// Example StorageClass snippet:
+	//    - name: BasePath
+	//      value: '/mnt/{{ index .Node.Labels "storage.example.com/disk" }}'
 	KeyPVBasePath = "BasePath"
 
+	//KeyPVBasePaths defines a list of base directories for hostpath
+	// volumes. If set, it takes precedence over BasePath, and the base
+	// directory of each volume is picked using the BasePathPolicy. The
+	// entries may refer to the node in the same way as the BasePath.
+	// Example StorageClass snippet:
+	//    - name: BasePaths
+	//      list:
+	//        - "/mnt/disk1"
+	//        - "/mnt/disk2"
+	KeyPVBasePaths = "BasePaths"
+
+	//KeyBasePathPolicy defines how the base directory of a volume is
+	// picked from the BasePaths. Supported values are LeastUsed,
+	// FewestVolumes and RoundRobin. Default is FewestVolumes.
+	KeyBasePathPolicy = "BasePathPolicy"
+
 	//KeyPVFSType defines filesystem type to be used with devices
 	// and can be configured via the StorageClass annotations.
 	KeyPVFSType = "FSType"
@@ -100,15 +128,130 @@ const (
 
 	//KeyPVRelativePath defines the alternate folder name under the BasePath
 	// By default, the pv name will be used as the folder name.
-	// KeyPVBasePath can be useful for providing the same underlying folder
-	// name for all replicas in a Statefulset.
-	// Will be a property of the PVC annotations.
-	//KeyPVRelativePath = "RelativePath"
+	// KeyPVRelativePath can be useful for providing the same underlying folder
+	// name for all replicas in a Statefulset, or readable folder names.
+	// The value may refer to {{ .PVName }}, {{ .PVCName }} and
+	// {{ .PVCNamespace }}, and must stay within the BasePath.
+	// Example StorageClass snippet:
+	//    - name: RelativePath
+	//      value: "{{ .PVCNamespace }}/{{ .PVCName }}"
+	KeyPVRelativePath = "RelativePath"
+
 	//KeyPVAbsolutePath specifies a complete hostpath instead of
 	// auto-generating using BasePath and RelativePath. This option
 	// is specified with PVC and is useful for granting shared access
-	// to underlying hostpaths across multiple pods.
-	//KeyPVAbsolutePath = "AbsolutePath"
+	// to underlying hostpaths across multiple pods. The hostpath must
+	// already exist and be under one of the AllowedAbsolutePaths.
+	KeyPVAbsolutePath = "AbsolutePath"
+
+	//KeyAllowedAbsolutePaths defines the directories under which the
+	// AbsolutePath of a volume is allowed. AbsolutePath is rejected if
+	// this list is not set on the StorageClass.
+	// Example StorageClass snippet:
+	//    - name: AllowedAbsolutePaths
+	//      list:
+	//        - "/mnt/infra"
+	KeyAllowedAbsolutePaths = "AllowedAbsolutePaths"
+
+	//KeyDirectoryMode defines the permission bits of the hostpath volume
+	// directory, in octal. Default is 0777.
+	// Example StorageClass snippet:
+	//    - name: DirectoryMode
+	//      value: "0750"
+	KeyDirectoryMode = "DirectoryMode"
+
+	//KeyDirectoryUID and KeyDirectoryGID define the numeric owner and
+	// group of the hostpath volume directory. By default, the directory
+	// is owned by the user of the helper pod.
+	// Example StorageClass snippet:
+	//    - name: DirectoryUID
+	//      value: "1000"
+	//    - name: DirectoryGID
+	//      value: "2000"
+	KeyDirectoryUID = "DirectoryUID"
+	KeyDirectoryGID = "DirectoryGID"
+
+	//KeyDirectoryDefaultACL defines the default ACL set on the hostpath
+	// volume directory with setfacl. The files created in the volume
+	// inherit the default ACL.
+	// Example StorageClass snippet:
+	//    - name: DirectoryDefaultACL
+	//      value: "g::rwx,o::-"
+	KeyDirectoryDefaultACL = "DirectoryDefaultACL"
+
+	//KeySELinux enables the relabeling of the hostpath volume directory
+	// with an SELinux context. The context defaults to the
+	// container_file_t type. Relabeling is skipped on nodes on which
+	// SELinux is disabled.
+	// Example StorageClass snippet:
+	//    - name: SELinux
+	//      enabled: "true"
+	//      value: "system_u:object_r:container_file_t:s0"
+	KeySELinux = "SELinux"
+
+	//KeySeed defines the source with which a new hostpath volume is
+	// populated. One of the following data fields must be set:
+	//   configMap: name of a ConfigMap in the namespace of the PVC
+	//   secret: name of a Secret in the namespace of the PVC
+	//   tarball: path of a tar archive on the node, under one of the
+	//            AllowedSeedPaths
+	// Example StorageClass snippet:
+	//    - name: Seed
+	//      data:
+	//        configMap: "app-skeleton"
+	KeySeed = "Seed"
+
+	//KeyAllowedSeedPaths defines the directories under which the
+	// tarball of the Seed is allowed. A tarball is rejected if this
+	// list is not set on the StorageClass.
+	// Example StorageClass snippet:
+	//    - name: AllowedSeedPaths
+	//      list:
+	//        - "/var/openebs/seeds"
+	KeyAllowedSeedPaths = "AllowedSeedPaths"
+
+	KeySeedConfigMap = "configMap"
+	KeySeedSecret    = "secret"
+	KeySeedTarball   = "tarball"
+
+	//KeyTrash enables the trash mode of hostpath volumes. When a volume
+	// is deleted, its directory is moved into <BasePath>/.trash instead
+	// of being removed. The trashed directory is removed after the
+	// retention period, or earlier if the available space of the
+	// BasePath drops below minAvailable, given as a quantity or as a
+	// percentage of the filesystem size.
+	// Example StorageClass snippet:
+	//    - name: Trash
+	//      enabled: "true"
+	//      data:
+	//        retention: "72h"
+	//        minAvailable: "10%"
+	KeyTrash = "Trash"
+
+	KeyTrashRetention    = "retention"
+	KeyTrashMinAvailable = "minAvailable"
+
+	//KeyWipePolicy defines how the data of a volume is overwritten
+	// before the volume is deleted. Supported values are None, ZeroFill
+	// and Shred for hostpath volumes, and additionally Wipefs and
+	// Discard for device volumes in Block mode. Default is None.
+	// Example StorageClass snippet:
+	//    - name: WipePolicy
+	//      value: "ZeroFill"
+	KeyWipePolicy = "WipePolicy"
+
+	//KeyNodeGonePolicy defines how a hostpath volume is deleted if no
+	// node matches the node affinity of its PV. Supported values are
+	// Fail, Forget and Wait. Default is Fail. With Forget, the PV is
+	// deleted without cleaning up its directory after the gracePeriod.
+	// Example StorageClass snippet:
+	//    - name: NodeGonePolicy
+	//      value: "Forget"
+	//      data:
+	//        gracePeriod: "24h"
+	KeyNodeGonePolicy = "NodeGonePolicy"
+
+	KeyNodeGoneGracePeriod = "gracePeriod"
 
 	//KeyXFSQuota enables/sets parameters for XFS Quota.
 	// Example StorageClass snippet:
@@ -128,6 +271,31 @@ const (
 	//        hardLimitGrace: "85%"
 	KeyEXT4Quota = "EXT4Quota"
 
+	//KeyAllowedPVCConfigs defines the cas.openebs.io/config keys
+	// which can be set via the PVC annotations. The PVC config is merged
+	// on top of the StorageClass config. Any other key set on the PVC
+	// will fail the provisioning.
+	// Example StorageClass snippet:
+	//    - name: AllowedPVCConfigs
+	//      list:
+	//        - "XFSQuota"
+	KeyAllowedPVCConfigs = "AllowedPVCConfigs"
+
+	//KeyHelperImage overrides the helper image with which the helper
+	// pods of the volumes are launched.
+	// Example StorageClass snippet:
+	//    - name: HelperImage
+	//      value: "registry.example.com/openebs/linux-utils:4.1.0"
+	KeyHelperImage = "HelperImage"
+
+	//KeyHelperPodTemplate defines the name of the ConfigMap, in the
+	// namespace of the provisioner, with the helper pod template of the
+	// volumes. It overrides the default helper pod template.
+	// Example StorageClass snippet:
+	//    - name: HelperPodTemplate
+	//      value: "helper-pod-template"
+	KeyHelperPodTemplate = "HelperPodTemplate"
+
 	KeyQuotaSoftLimit = "softLimitGrace"
 	KeyQuotaHardLimit = "hardLimitGrace"
 )
@@ -140,8 +308,61 @@ const (
 	// k8sNodeLabelKeyHostname is the label key used by Kubernetes
 	// to store the hostname on the node resource.
 	k8sNodeLabelKeyHostname = "kubernetes.io/hostname"
+
+	// defaultSELinuxContext is the SELinux context of the hostpath
+	// volume directory if SELinux relabeling is enabled without a context.
+	defaultSELinuxContext = "system_u:object_r:container_file_t:s0"
+
+	// defaultTrashRetention is the period for which a trashed volume
+	// is kept if the retention is not set.
+	defaultTrashRetention = 7 * 24 * time.Hour
+
+	// defaultNodeGoneGracePeriod is the period after which the PV of a
+	// gone node is forgotten if the gracePeriod is not set.
+	defaultNodeGoneGracePeriod = 24 * time.Hour
+
+	// defaultDirectoryMode is the permission bits of the hostpath
+	// volume directory if DirectoryMode is not set.
+	defaultDirectoryMode = "0777"
+)
+
+var (
+	// validPathRegex restricts the characters of the RelativePath and
+	// AbsolutePath, as the path is passed on to the helper pod commands.
+	validPathRegex = regexp.MustCompile(`^[a-zA-Z0-9._/-]+$`)
+
+	// validDirectoryModeRegex matches the octal permission bits.
+	validDirectoryModeRegex = regexp.MustCompile(`^[0-7]{3,4}$`)
+
+	// validSELinuxContextRegex matches an SELinux context of the form
+	// user:role:type[:level].
+	validSELinuxContextRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+:[a-zA-Z0-9_]+:[a-zA-Z0-9_]+(:[a-zA-Z0-9.,:-]+)?$`)
+
+	// validACLRegex matches a comma separated list of ACL entries of
+	// the form <type>:<qualifier>:<perms>, as accepted by setfacl.
+	validACLRegex = regexp.MustCompile(`^(u|user|g|group|m|mask|o|other):[a-zA-Z0-9._-]*:[rwxX-]{1,4}` +
+		`(,(u|user|g|group|m|mask|o|other):[a-zA-Z0-9._-]*:[rwxX-]{1,4})*$`)
 )
 
+// getStorageClassConfig returns the config of the StorageClass of the
+// PV, without the config of its PVC. No config is returned if the PV
+// has no StorageClass, or if the StorageClass no longer exists.
+func (p *Provisioner) getStorageClassConfig(ctx context.Context, pv *corev1.PersistentVolume) (*VolumeConfig, error) {
+	scName := pv.Spec.StorageClassName
+	if scName == "" {
+		return nil, nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &scName},
+	}
+	volumeConfig, err := p.getVolumeConfig(ctx, pv.Name, pvc)
+	if k8serrors.IsNotFound(errors.Cause(err)) {
+		klog.Infof("Storageclass %v of volume %v not found", scName, pv.Name)
+		return nil, nil
+	}
+	return volumeConfig, err
+}
+
 // GetVolumeConfig creates a new VolumeConfig struct by
 // parsing and merging the configuration provided in the PVC
 // annotation - cas.openebs.io/config with the
@@ -169,16 +390,20 @@ func (p *Provisioner) GetVolumeConfig(ctx context.Context, pvName string, pvc *c
 		}
 	}
 
-	//TODO : extract and merge the cas volume config from pvc
-	//This block can be added once validation checks are added
-	// as to the type of config that can be passed via PVC
-	//pvcCASConfigStr := pvc.ObjectMeta.Annotations[string(mconfig.CASConfigKey)]
-	//if len(strings.TrimSpace(pvcCASConfigStr)) != 0 {
-	//	pvcCASConfig, err := cast.UnMarshallToConfig(pvcCASConfigStr)
-	//	if err == nil {
-	//		pvConfig = cast.MergeConfig(pvcCASConfig, pvConfig)
-	//	}
-	//}
+	// extract and merge the cas volume config from pvc. Only the
+	// config keys allowed by the StorageClass can be set via PVC.
+	pvcCASConfigStr := pvc.ObjectMeta.Annotations[string(mconfig.CASConfigKey)]
+	klog.V(4).Infof("PVC %v has config:%v", pvc.ObjectMeta.Name, pvcCASConfigStr)
+	if len(strings.TrimSpace(pvcCASConfigStr)) != 0 {
+		pvcCASConfig, err := cast.UnMarshallToConfig(pvcCASConfigStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get config: invalid pvc config {%v}", pvcCASConfigStr)
+		}
+		pvConfig, err = mergePVCConfig(pvcCASConfig, pvConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get config for pvc {%v} with storageclass {%v}", pvc.ObjectMeta.Name, *scName)
+		}
+	}
 
 	pvConfigMap, err := cast.ConfigToMap(pvConfig)
 	if err != nil {
@@ -196,12 +421,13 @@ func (p *Provisioner) GetVolumeConfig(ctx context.Context, pvName string, pvc *c
 	}
 
 	c := &VolumeConfig{
-		pvName:     pvName,
-		pvcName:    pvc.ObjectMeta.Name,
-		scName:     *scName,
-		options:    pvConfigMap,
-		configData: dataPvConfigMap,
-		configList: listPvConfigMap,
+		pvName:       pvName,
+		pvcName:      pvc.ObjectMeta.Name,
+		pvcNamespace: pvc.ObjectMeta.Namespace,
+		scName:       *scName,
+		options:      pvConfigMap,
+		configData:   dataPvConfigMap,
+		configList:   listPvConfigMap,
 	}
 	return c, nil
 }
@@ -269,8 +495,8 @@ func (c *VolumeConfig) GetNodeAffinityLabelKeys() []string {
 
 // GetPath returns a valid PV path based on the configuration
 // or an error. The Path is constructed using the following rules:
-// If AbsolutePath is specified return it. (Future)
-// If PVPath is specified, suffix it with BasePath and return it. (Future)
+// If AbsolutePath is specified return it.
+// If RelativePath is specified, suffix it with BasePath and return it.
 // If neither of above are specified, suffix the PVName to BasePath
 //
 //	and return it
@@ -279,35 +505,432 @@ func (c *VolumeConfig) GetNodeAffinityLabelKeys() []string {
 //
 //	and matches the filters specified in StorageClass.
 func (c *VolumeConfig) GetPath() (string, error) {
-	//This feature need to be supported with some more
-	// security checks are in place, so that rouge pods
-	// don't get access to node directories.
-	//absolutePath := c.getValue(KeyPVAbsolutePath)
-	//if len(strings.TrimSpace(absolutePath)) != 0 {
-	//	return c.validatePath(absolutePath)
-	//}
+	rootPath, err := c.GetRootPath()
+	if err != nil {
+		return "", err
+	}
+
+	if c.IsAbsolutePath() {
+		return c.getValue(KeyPVAbsolutePath), nil
+	}
+
+	pvRelPath, err := c.getRelativePath()
+	if err != nil {
+		return "", err
+	}
+
+	return hostpath.NewBuilder().
+		WithPathJoin(rootPath, pvRelPath).
+		WithCheckf(hostpath.IsNonRoot(), "path should not be a root directory: %s/%s", rootPath, pvRelPath).
+		ValidateAndBuild()
+}
+
+// GetRootPath returns the directory under which the PV path is
+// created. This is the BasePath, or the entry of AllowedAbsolutePaths
+// containing the AbsolutePath. The helper pods mount the root path
+// to verify that the PV path does not traverse outside of it.
+func (c *VolumeConfig) GetRootPath() (string, error) {
+	if c.IsAbsolutePath() {
+		absolutePath := c.getValue(KeyPVAbsolutePath)
+		if !validPathRegex.MatchString(absolutePath) ||
+			!filepath.IsAbs(absolutePath) ||
+			filepath.Clean(absolutePath) != absolutePath {
+			return "", errors.Errorf("failed to get path: invalid absolute path {%v}", absolutePath)
+		}
+
+		for _, allowedPath := range c.getList(KeyAllowedAbsolutePaths) {
+			allowedPath = filepath.Clean(strings.TrimSpace(allowedPath))
+			if !filepath.IsAbs(allowedPath) {
+				continue
+			}
+			if isSubPath(allowedPath, absolutePath) {
+				return hostpath.NewBuilder().
+					WithPath(allowedPath).
+					WithCheckf(hostpath.IsNonRoot(), "allowed absolute path should not be a root directory: %s", allowedPath).
+					ValidateAndBuild()
+			}
+		}
+		return "", errors.Errorf("failed to get path: absolute path {%v} is not under the allowed absolute paths %v",
+			absolutePath, c.getList(KeyAllowedAbsolutePaths))
+	}
 
 	basePath := c.getValue(KeyPVBasePath)
 	if strings.TrimSpace(basePath) == "" {
 		return "", errors.Errorf("failed to get path: base path is empty")
 	}
+	return filepath.Clean(basePath), nil
+}
+
+// GetBasePaths returns the BasePaths configured in the StorageClass.
+// Default is nil, in which case BasePath is used.
+func (c *VolumeConfig) GetBasePaths() ([]string, error) {
+	return validateBasePaths(c.getList(KeyPVBasePaths))
+}
+
+// getNodeBasePaths returns the BasePaths, or else the BasePath, after
+// resolving the references to the node. The config is not modified.
+func (c *VolumeConfig) getNodeBasePaths(node *corev1.Node) ([]string, error) {
+	basePaths := c.getList(KeyPVBasePaths)
+	if len(basePaths) == 0 {
+		basePaths = []string{c.getValue(KeyPVBasePath)}
+	}
+	resolvedPaths := make([]string, 0, len(basePaths))
+	for _, basePath := range basePaths {
+		resolvedPath, err := resolveBasePath(basePath, node)
+		if err != nil {
+			return nil, err
+		}
+		resolvedPaths = append(resolvedPaths, resolvedPath)
+	}
+	return validateBasePaths(resolvedPaths)
+}
 
-	//This feature need to be supported after the
-	// security checks are in place.
-	//pvRelPath := c.getValue(KeyPVRelativePath)
-	//if len(strings.TrimSpace(pvRelPath)) == 0 {
-	//	pvRelPath = c.pvName
-	//}
+// resolveBasePaths resolves the references to the node in the
+// BasePath and BasePaths against the node of the volume.
+func (c *VolumeConfig) resolveBasePaths(node *corev1.Node) error {
+	basePath, err := resolveBasePath(c.getValue(KeyPVBasePath), node)
+	if err != nil {
+		return err
+	}
+	if basePath != c.getValue(KeyPVBasePath) {
+		c.setBasePath(basePath)
+	}
 
-	pvRelPath := c.pvName
-	//path := filepath.Join(basePath, pvRelPath)
+	basePaths := c.getList(KeyPVBasePaths)
+	if len(basePaths) == 0 {
+		return nil
+	}
+	resolvedPaths := make([]string, 0, len(basePaths))
+	for _, basePath := range basePaths {
+		resolvedPath, err := resolveBasePath(basePath, node)
+		if err != nil {
+			return err
+		}
+		resolvedPaths = append(resolvedPaths, resolvedPath)
+	}
+	c.configList[KeyPVBasePaths] = resolvedPaths
+	return nil
+}
+
+// resolveBasePath returns the base path after resolving the references
+// to the name and labels of the node. The base path is returned as is,
+// if it is not a template.
+func resolveBasePath(basePath string, node *corev1.Node) (string, error) {
+	if !strings.Contains(basePath, "{{") {
+		return basePath, nil
+	}
 
+	tmpl, err := template.New(KeyPVBasePath).Option("missingkey=error").Parse(basePath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get path: invalid base path {%v}", basePath)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]interface{}{
+		"Node": map[string]interface{}{
+			"Name":   node.Name,
+			"Labels": node.Labels,
+		},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get path: invalid base path {%v}", basePath)
+	}
+
+	// An empty path element is the result of a label which is not
+	// set on the node.
+	resolvedPath := strings.TrimSpace(buf.String())
+	if strings.Contains(resolvedPath, "//") ||
+		(strings.HasSuffix(resolvedPath, "/") && !strings.HasSuffix(basePath, "/")) {
+		return "", errors.Errorf("failed to get path: base path {%v} resolved to {%v} on node %v", basePath, resolvedPath, node.Name)
+	}
+	if !validPathRegex.MatchString(resolvedPath) || !filepath.IsAbs(resolvedPath) {
+		return "", errors.Errorf("failed to get path: base path {%v} resolved to invalid path {%v} on node %v", basePath, resolvedPath, node.Name)
+	}
 	return hostpath.NewBuilder().
-		WithPathJoin(basePath, pvRelPath).
-		WithCheckf(hostpath.IsNonRoot(), "path should not be a root directory: %s/%s", basePath, pvRelPath).
+		WithPath(filepath.Clean(resolvedPath)).
+		WithCheckf(hostpath.IsNonRoot(), "base path should not be a root directory: %s", resolvedPath).
 		ValidateAndBuild()
 }
 
+// validateBasePaths returns the cleaned and de-duplicated base paths,
+// or an error if any of them is not a valid absolute path.
+func validateBasePaths(paths []string) ([]string, error) {
+	basePaths := []string{}
+	for _, basePath := range paths {
+		basePath = strings.TrimSpace(basePath)
+		if !validPathRegex.MatchString(basePath) || !filepath.IsAbs(basePath) {
+			return nil, errors.Errorf("failed to get path: invalid base path {%v}", basePath)
+		}
+		basePath, err := hostpath.NewBuilder().
+			WithPath(filepath.Clean(basePath)).
+			WithCheckf(hostpath.IsNonRoot(), "base path should not be a root directory: %s", basePath).
+			ValidateAndBuild()
+		if err != nil {
+			return nil, err
+		}
+		if !util.ContainsString(basePaths, basePath) {
+			basePaths = append(basePaths, basePath)
+		}
+	}
+	if len(basePaths) == 0 {
+		return nil, nil
+	}
+	return basePaths, nil
+}
+
+// GetBasePathPolicy returns the BasePathPolicy configured in the
+// StorageClass. Default is FewestVolumes.
+func (c *VolumeConfig) GetBasePathPolicy() (string, error) {
+	policy := strings.TrimSpace(c.getValue(KeyBasePathPolicy))
+	switch policy {
+	case "":
+		return BasePathPolicyFewestVolumes, nil
+	case BasePathPolicyLeastUsed, BasePathPolicyFewestVolumes, BasePathPolicyRoundRobin:
+		return policy, nil
+	}
+	return "", errors.Errorf("invalid base path policy {%v}", policy)
+}
+
+// setBasePath sets the BasePath under which the PV path is created.
+// It is used to set the base directory picked from the BasePaths.
+func (c *VolumeConfig) setBasePath(basePath string) {
+	configObj := map[string]string{}
+	if existing, ok := util.GetNestedField(c.options, KeyPVBasePath).(map[string]string); ok {
+		for k, v := range existing {
+			configObj[k] = v
+		}
+	}
+	configObj[string(mconfig.ValuePTP)] = basePath
+	if c.options == nil {
+		c.options = map[string]interface{}{}
+	}
+	c.options[KeyPVBasePath] = configObj
+}
+
+// IsAbsolutePath returns true if the PV path is set using AbsolutePath.
+func (c *VolumeConfig) IsAbsolutePath() bool {
+	return len(strings.TrimSpace(c.getValue(KeyPVAbsolutePath))) != 0
+}
+
+// getRelativePath returns the RelativePath after resolving the PV and
+// PVC references. The PV name is returned if RelativePath is not set.
+func (c *VolumeConfig) getRelativePath() (string, error) {
+	relPathTemplate := c.getValue(KeyPVRelativePath)
+	if len(strings.TrimSpace(relPathTemplate)) == 0 {
+		return c.pvName, nil
+	}
+
+	tmpl, err := template.New(KeyPVRelativePath).Option("missingkey=error").Parse(relPathTemplate)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get path: invalid relative path {%v}", relPathTemplate)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]string{
+		"PVName":       c.pvName,
+		"PVCName":      c.pvcName,
+		"PVCNamespace": c.pvcNamespace,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get path: invalid relative path {%v}", relPathTemplate)
+	}
+
+	relPath := buf.String()
+	cleanPath := filepath.Clean(relPath)
+	if !validPathRegex.MatchString(relPath) ||
+		filepath.IsAbs(relPath) ||
+		cleanPath == "." ||
+		cleanPath == ".." ||
+		strings.HasPrefix(cleanPath, "../") {
+		return "", errors.Errorf("failed to get path: relative path {%v} should be within the base path", relPath)
+	}
+	// The trash and the snapshots of the volumes are kept under the
+	// BasePath, and must not be used by or removed with a volume.
+	if reserved := strings.SplitN(cleanPath, "/", 2)[0]; reserved == trashDir || reserved == defaultSnapshotDir {
+		return "", errors.Errorf("failed to get path: relative path {%v} is under the reserved directory %v", relPath, reserved)
+	}
+	return cleanPath, nil
+}
+
+// isSubPath returns true if path is a sub directory of rootPath.
+func isSubPath(rootPath, path string) bool {
+	relPath, err := filepath.Rel(rootPath, path)
+	if err != nil {
+		return false
+	}
+	return relPath != "." && relPath != ".." && !strings.HasPrefix(relPath, "../")
+}
+
+// GetDirectoryMode returns the DirectoryMode configured in the
+// StorageClass. Default is 0777.
+func (c *VolumeConfig) GetDirectoryMode() (string, error) {
+	mode := strings.TrimSpace(c.getValue(KeyDirectoryMode))
+	if mode == "" {
+		return defaultDirectoryMode, nil
+	}
+	if !validDirectoryModeRegex.MatchString(mode) {
+		return "", errors.Errorf("invalid directory mode {%v}", mode)
+	}
+	if len(mode) == 3 {
+		mode = "0" + mode
+	}
+	return mode, nil
+}
+
+// GetDirectoryOwner returns the DirectoryUID and DirectoryGID configured
+// in the StorageClass. Default is empty, in which case the owner or the
+// group of the directory is not changed.
+func (c *VolumeConfig) GetDirectoryOwner() (string, string, error) {
+	uid := strings.TrimSpace(c.getValue(KeyDirectoryUID))
+	gid := strings.TrimSpace(c.getValue(KeyDirectoryGID))
+	for key, id := range map[string]string{KeyDirectoryUID: uid, KeyDirectoryGID: gid} {
+		if id == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(id, 10, 32); err != nil {
+			return "", "", errors.Errorf("invalid %v {%v}: should be a numeric id", key, id)
+		}
+	}
+	return uid, gid, nil
+}
+
+// GetDirectoryDefaultACL returns the DirectoryDefaultACL configured in
+// the StorageClass. Default is empty.
+func (c *VolumeConfig) GetDirectoryDefaultACL() (string, error) {
+	acl := strings.TrimSpace(c.getValue(KeyDirectoryDefaultACL))
+	if acl != "" && !validACLRegex.MatchString(acl) {
+		return "", errors.Errorf("invalid directory default acl {%v}", acl)
+	}
+	return acl, nil
+}
+
+// GetSELinuxContext returns the SELinux context to be applied to the
+// volume directory. An empty context is returned if SELinux relabeling
+// is not enabled in the StorageClass.
+func (c *VolumeConfig) GetSELinuxContext() (string, error) {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(c.getEnabled(KeySELinux)))
+	if err != nil || !enabled {
+		return "", nil
+	}
+	selinuxContext := strings.TrimSpace(c.getValue(KeySELinux))
+	if selinuxContext == "" {
+		return defaultSELinuxContext, nil
+	}
+	if !validSELinuxContextRegex.MatchString(selinuxContext) {
+		return "", errors.Errorf("invalid selinux context {%v}", selinuxContext)
+	}
+	return selinuxContext, nil
+}
+
+// GetSeed returns the source with which the volume is populated. No
+// source is returned if Seed is not set in the StorageClass or PVC.
+func (c *VolumeConfig) GetSeed() (*volumeSeed, error) {
+	seedData := c.getData(KeySeed)
+	var seeds []*volumeSeed
+	for _, kind := range []string{KeySeedConfigMap, KeySeedSecret, KeySeedTarball} {
+		if name := strings.TrimSpace(seedData[kind]); name != "" {
+			seeds = append(seeds, &volumeSeed{kind: kind, name: name})
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	if len(seeds) > 1 {
+		return nil, errors.Errorf("invalid seed: only one of %v, %v and %v can be set",
+			KeySeedConfigMap, KeySeedSecret, KeySeedTarball)
+	}
+
+	seed := seeds[0]
+	if seed.kind != KeySeedTarball {
+		return seed, nil
+	}
+	if !validPathRegex.MatchString(seed.name) ||
+		!filepath.IsAbs(seed.name) ||
+		filepath.Clean(seed.name) != seed.name {
+		return nil, errors.Errorf("invalid seed: invalid tarball path {%v}", seed.name)
+	}
+	for _, allowedPath := range c.getList(KeyAllowedSeedPaths) {
+		allowedPath = filepath.Clean(strings.TrimSpace(allowedPath))
+		if filepath.IsAbs(allowedPath) && allowedPath != "/" && isSubPath(allowedPath, seed.name) {
+			return seed, nil
+		}
+	}
+	return nil, errors.Errorf("invalid seed: tarball {%v} is not under the allowed seed paths %v",
+		seed.name, c.getList(KeyAllowedSeedPaths))
+}
+
+// GetWipePolicy returns the wipe policy of the volume. None is
+// returned if the WipePolicy is not set.
+func (c *VolumeConfig) GetWipePolicy() (string, error) {
+	policy := strings.TrimSpace(c.getValue(KeyWipePolicy))
+	switch policy {
+	case "":
+		return WipePolicyNone, nil
+	case WipePolicyNone, WipePolicyZeroFill, WipePolicyShred, WipePolicyWipefs, WipePolicyDiscard:
+		return policy, nil
+	}
+	return "", errors.Errorf("invalid wipe policy {%v}", policy)
+}
+
+// GetNodeGonePolicy returns the node gone policy, and the grace period
+// of the Forget policy. Fail is returned if the NodeGonePolicy is not
+// set.
+func (c *VolumeConfig) GetNodeGonePolicy() (string, time.Duration, error) {
+	policy := strings.TrimSpace(c.getValue(KeyNodeGonePolicy))
+	switch policy {
+	case "":
+		return NodeGonePolicyFail, 0, nil
+	case NodeGonePolicyFail, NodeGonePolicyWait:
+		return policy, 0, nil
+	case NodeGonePolicyForget:
+		gracePeriod := defaultNodeGoneGracePeriod
+		if value := strings.TrimSpace(c.getDataField(KeyNodeGonePolicy, KeyNodeGoneGracePeriod)); value != "" {
+			var err error
+			gracePeriod, err = time.ParseDuration(value)
+			if err != nil || gracePeriod < 0 {
+				return "", 0, errors.Errorf("invalid node gone grace period {%v}", value)
+			}
+		}
+		return policy, gracePeriod, nil
+	}
+	return "", 0, errors.Errorf("invalid node gone policy {%v}", policy)
+}
+
+// GetHelperImage returns the helper image of the volume, or an empty
+// image if the helper image of the provisioner is to be used.
+func (c *VolumeConfig) GetHelperImage() string {
+	return strings.TrimSpace(c.getValue(KeyHelperImage))
+}
+
+// GetHelperPodTemplate returns the name of the ConfigMap of the helper
+// pod template of the volume, or an empty name if the default helper
+// pod template is to be used.
+func (c *VolumeConfig) GetHelperPodTemplate() string {
+	return strings.TrimSpace(c.getValue(KeyHelperPodTemplate))
+}
+
+// GetTrashPolicy returns the trash policy configured in the
+// StorageClass. No policy is returned if the trash mode is not enabled.
+func (c *VolumeConfig) GetTrashPolicy() (*trashPolicy, error) {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(c.getEnabled(KeyTrash)))
+	if err != nil || !enabled {
+		return nil, nil
+	}
+
+	policy := &trashPolicy{retention: defaultTrashRetention}
+	if retention := strings.TrimSpace(c.getDataField(KeyTrash, KeyTrashRetention)); retention != "" {
+		policy.retention, err = time.ParseDuration(retention)
+		if err != nil || policy.retention < 0 {
+			return nil, errors.Errorf("invalid trash retention {%v}", retention)
+		}
+	}
+	if minAvailable := strings.TrimSpace(c.getDataField(KeyTrash, KeyTrashMinAvailable)); minAvailable != "" {
+		if _, _, err := parseMinAvailable(minAvailable); err != nil {
+			return nil, err
+		}
+		policy.minAvailable = minAvailable
+	}
+	return policy, nil
+}
+
 func (c *VolumeConfig) IsXfsQuotaEnabled() bool {
 	xfsQuotaEnabled := c.getEnabled(KeyXFSQuota)
 	xfsQuotaEnabled = strings.TrimSpace(xfsQuotaEnabled)
@@ -474,6 +1097,57 @@ func GetImagePullSecrets(s string) []corev1.LocalObjectReference {
 	return list
 }
 
+// mergePVCConfig merges the config set via the PVC annotations on top
+// of the StorageClass config. Every key set on the PVC must be present
+// in the AllowedPVCConfigs list of the StorageClass. The fields that
+// are not set on the PVC config are retained from the StorageClass.
+func mergePVCConfig(pvcConfig, scConfig []mconfig.Config) ([]mconfig.Config, error) {
+	var allowedKeys []string
+	for _, config := range scConfig {
+		if strings.TrimSpace(config.Name) == KeyAllowedPVCConfigs {
+			allowedKeys = config.List
+		}
+	}
+
+	for i, config := range pvcConfig {
+		configName := strings.TrimSpace(config.Name)
+		if configName == KeyAllowedPVCConfigs ||
+			configName == KeyAllowedAbsolutePaths ||
+			configName == KeyAllowedSeedPaths ||
+			!util.ContainsString(allowedKeys, configName) {
+			return nil, errors.Errorf("config key %q is not allowed to be set on the PVC", configName)
+		}
+
+		for _, sc := range scConfig {
+			if strings.TrimSpace(sc.Name) != configName {
+				continue
+			}
+			if config.Enabled == "" {
+				config.Enabled = sc.Enabled
+			}
+			if config.Value == "" {
+				config.Value = sc.Value
+			}
+			if len(config.List) == 0 {
+				config.List = sc.List
+			}
+			if len(sc.Data) != 0 {
+				data := map[string]string{}
+				for k, v := range sc.Data {
+					data[k] = v
+				}
+				for k, v := range config.Data {
+					data[k] = v
+				}
+				config.Data = data
+			}
+		}
+		pvcConfig[i] = config
+	}
+
+	return cast.MergeConfig(pvcConfig, scConfig), nil
+}
+
 func dataConfigToMap(pvConfig []mconfig.Config) (map[string]interface{}, error) {
 	m := map[string]interface{}{}
 
@@ -18,11 +18,17 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestGetImagePullSecrets(t *testing.T) {
@@ -135,3 +141,811 @@ func Test_listConfigToMap(t *testing.T) {
 		})
 	}
 }
+
+func TestMergePVCConfig(t *testing.T) {
+	scConfig := []mconfig.Config{
+		{Name: "StorageType", Value: "hostpath"},
+		{Name: "XFSQuota", Enabled: "true",
+			Data: map[string]string{
+				"softLimitGrace": "20%",
+				"hardLimitGrace": "80%",
+			},
+		},
+		{Name: "AllowedPVCConfigs", List: []string{"XFSQuota"}},
+	}
+
+	testCases := map[string]struct {
+		pvcConfig     []mconfig.Config
+		expectedValue []mconfig.Config
+		expectError   string
+	}{
+		"allowed key is merged on top of storageclass config": {
+			pvcConfig: []mconfig.Config{
+				{Name: "XFSQuota", Data: map[string]string{"hardLimitGrace": "90%"}},
+			},
+			expectedValue: []mconfig.Config{
+				{Name: "XFSQuota", Enabled: "true",
+					Data: map[string]string{
+						"softLimitGrace": "20%",
+						"hardLimitGrace": "90%",
+					},
+				},
+				{Name: "StorageType", Value: "hostpath"},
+				{Name: "AllowedPVCConfigs", List: []string{"XFSQuota"}},
+			},
+		},
+		"key not in allowlist": {
+			pvcConfig: []mconfig.Config{
+				{Name: "BasePath", Value: "/etc"},
+			},
+			expectError: "BasePath",
+		},
+		"allowlist cannot be set on the pvc": {
+			pvcConfig: []mconfig.Config{
+				{Name: "AllowedPVCConfigs", List: []string{"BasePath"}},
+			},
+			expectError: "AllowedPVCConfigs",
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actualValue, err := mergePVCConfig(v.pvcConfig, scConfig)
+			if v.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectError) {
+					t.Fatalf("expected error about %q, but got %v", v.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if !reflect.DeepEqual(actualValue, v.expectedValue) {
+				t.Errorf("expected %v, but got %v", v.expectedValue, actualValue)
+			}
+		})
+	}
+}
+
+func TestGetVolumeConfigWithPVCConfig(t *testing.T) {
+	scName := "openebs-hostpath"
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: scName,
+			Annotations: map[string]string{
+				string(mconfig.CASConfigKey): `
+- name: StorageType
+  value: "hostpath"
+- name: XFSQuota
+  enabled: "true"
+  data:
+    softLimitGrace: "20%"
+- name: AllowedPVCConfigs
+  list:
+    - "XFSQuota"
+`,
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		pvcConfig   string
+		expectError string
+		expectGrace string
+	}{
+		"no pvc config": {
+			expectGrace: "20%",
+		},
+		"allowed pvc config": {
+			pvcConfig: `
+- name: XFSQuota
+  data:
+    softLimitGrace: "50%"
+`,
+			expectGrace: "50%",
+		},
+		"rejected pvc config": {
+			pvcConfig: `
+- name: BasePath
+  value: "/etc"
+`,
+			expectError: "BasePath",
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			p := &Provisioner{kubeClient: fake.NewSimpleClientset(sc)}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "pvcName",
+					Annotations: map[string]string{},
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					StorageClassName: &scName,
+				},
+			}
+			if v.pvcConfig != "" {
+				pvc.Annotations[string(mconfig.CASConfigKey)] = v.pvcConfig
+			}
+
+			volumeConfig, err := p.GetVolumeConfig(context.TODO(), "pvName", pvc)
+			if v.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), v.expectError) {
+					t.Fatalf("expected error about %q, but got %v", v.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if !volumeConfig.IsXfsQuotaEnabled() {
+				t.Errorf("expected XFSQuota to be enabled")
+			}
+			actualGrace := volumeConfig.getDataField(KeyXFSQuota, KeyQuotaSoftLimit)
+			if actualGrace != v.expectGrace {
+				t.Errorf("expected %s, but got %s", v.expectGrace, actualGrace)
+			}
+		})
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	fakeConfig := func(options map[string]string, allowedAbsolutePaths []string) *VolumeConfig {
+		c := &VolumeConfig{
+			pvName:       "pvName",
+			pvcName:      "pvcName",
+			pvcNamespace: "pvcNamespace",
+			options:      map[string]interface{}{},
+			configList:   map[string]interface{}{},
+		}
+		for k, v := range options {
+			c.options[k] = map[string]string{"value": v}
+		}
+		if allowedAbsolutePaths != nil {
+			c.configList[KeyAllowedAbsolutePaths] = allowedAbsolutePaths
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		config         *VolumeConfig
+		expectPath     string
+		expectRootPath string
+		expectError    bool
+	}{
+		"default path": {
+			config:         fakeConfig(map[string]string{KeyPVBasePath: "/var/openebs/local"}, nil),
+			expectPath:     "/var/openebs/local/pvName",
+			expectRootPath: "/var/openebs/local",
+		},
+		"empty base path": {
+			config:      fakeConfig(map[string]string{KeyPVBasePath: " "}, nil),
+			expectError: true,
+		},
+		"relative path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "{{ .PVCNamespace }}/{{ .PVCName }}",
+			}, nil),
+			expectPath:     "/var/openebs/local/pvcNamespace/pvcName",
+			expectRootPath: "/var/openebs/local",
+		},
+		"relative path with traversal": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "../../etc",
+			}, nil),
+			expectError: true,
+		},
+		"relative path with traversal inside base path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "data/../{{ .PVName }}",
+			}, nil),
+			expectPath:     "/var/openebs/local/pvName",
+			expectRootPath: "/var/openebs/local",
+		},
+		"relative path same as base path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "data/..",
+			}, nil),
+			expectError: true,
+		},
+		"relative path with absolute path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "/etc",
+			}, nil),
+			expectError: true,
+		},
+		"relative path with invalid characters": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "pv; rm -rf /",
+			}, nil),
+			expectError: true,
+		},
+		"relative path under trash": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: ".trash/{{ .PVName }}",
+			}, nil),
+			expectError: true,
+		},
+		"relative path under snapshots": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "data/../.snapshots",
+			}, nil),
+			expectError: true,
+		},
+		"relative path with reserved name nested": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "{{ .PVCNamespace }}/.trash",
+			}, nil),
+			expectPath:     "/var/openebs/local/pvcNamespace/.trash",
+			expectRootPath: "/var/openebs/local",
+		},
+		"relative path with unknown reference": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVRelativePath: "{{ .NodeName }}",
+			}, nil),
+			expectError: true,
+		},
+		"absolute path under allowed path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/mnt/infra/registry",
+			}, []string{"/mnt/data", "/mnt/infra/"}),
+			expectPath:     "/mnt/infra/registry",
+			expectRootPath: "/mnt/infra",
+		},
+		"absolute path without allowed paths": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/mnt/infra/registry",
+			}, nil),
+			expectError: true,
+		},
+		"absolute path outside allowed paths": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/mnt/infrastructure",
+			}, []string{"/mnt/infra"}),
+			expectError: true,
+		},
+		"absolute path with traversal": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/mnt/infra/../../etc",
+			}, []string{"/mnt/infra"}),
+			expectError: true,
+		},
+		"absolute path same as allowed path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/mnt/infra",
+			}, []string{"/mnt/infra"}),
+			expectError: true,
+		},
+		"absolute path under root allowed path": {
+			config: fakeConfig(map[string]string{
+				KeyPVBasePath:     "/var/openebs/local",
+				KeyPVAbsolutePath: "/etc",
+			}, []string{"/"}),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actualPath, err := v.config.GetPath()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got path %s", actualPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if actualPath != v.expectPath {
+				t.Errorf("expected path %s, but got %s", v.expectPath, actualPath)
+			}
+			actualRootPath, err := v.config.GetRootPath()
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if actualRootPath != v.expectRootPath {
+				t.Errorf("expected root path %s, but got %s", v.expectRootPath, actualRootPath)
+			}
+		})
+	}
+}
+
+func TestResolveBasePaths(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				"storage.example.com/disk": "nvme0",
+				"storage.example.com/pool": "../etc",
+			},
+		},
+	}
+	fakeConfig := func(basePath string, basePaths []string) *VolumeConfig {
+		c := &VolumeConfig{
+			pvName:     "pvName",
+			options:    map[string]interface{}{KeyPVBasePath: map[string]string{"value": basePath}},
+			configList: map[string]interface{}{},
+		}
+		if basePaths != nil {
+			c.configList[KeyPVBasePaths] = basePaths
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		config          *VolumeConfig
+		expectPath      string
+		expectBasePaths []string
+		expectError     bool
+	}{
+		"plain base path": {
+			config:     fakeConfig("/var/openebs/local/", nil),
+			expectPath: "/var/openebs/local/pvName",
+		},
+		"node label": {
+			config:     fakeConfig(`/mnt/{{ index .Node.Labels "storage.example.com/disk" }}`, nil),
+			expectPath: "/mnt/nvme0/pvName",
+		},
+		"node name": {
+			config:     fakeConfig("/var/openebs/{{ .Node.Name }}", nil),
+			expectPath: "/var/openebs/node-1/pvName",
+		},
+		"missing node label": {
+			config:      fakeConfig(`/mnt/{{ index .Node.Labels "storage.example.com/ssd" }}`, nil),
+			expectError: true,
+		},
+		"missing node label in the middle": {
+			config:      fakeConfig(`/mnt/{{ index .Node.Labels "storage.example.com/ssd" }}/local`, nil),
+			expectError: true,
+		},
+		"unknown reference": {
+			config:      fakeConfig("/mnt/{{ .Node.Zone }}", nil),
+			expectError: true,
+		},
+		"root base path": {
+			config:      fakeConfig(`/{{ index .Node.Labels "storage.example.com/pool" }}/..`, nil),
+			expectError: true,
+		},
+		"node label in base paths": {
+			config:          fakeConfig("/var/openebs/local", []string{`/mnt/{{ index .Node.Labels "storage.example.com/disk" }}`, "/mnt/disk2"}),
+			expectPath:      "/var/openebs/local/pvName",
+			expectBasePaths: []string{"/mnt/nvme0", "/mnt/disk2"},
+		},
+		"missing node label in base paths": {
+			config:      fakeConfig("/var/openebs/local", []string{`/mnt/{{ index .Node.Labels "storage.example.com/ssd" }}`}),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			err := v.config.resolveBasePaths(node)
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			actualPath, err := v.config.GetPath()
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if actualPath != v.expectPath {
+				t.Errorf("expected path %s, but got %s", v.expectPath, actualPath)
+			}
+			actualBasePaths, err := v.config.GetBasePaths()
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if !reflect.DeepEqual(actualBasePaths, v.expectBasePaths) {
+				t.Errorf("expected base paths %v, but got %v", v.expectBasePaths, actualBasePaths)
+			}
+		})
+	}
+}
+
+func TestGetDirectoryPermissions(t *testing.T) {
+	fakeConfig := func(options map[string]string) *VolumeConfig {
+		c := &VolumeConfig{options: map[string]interface{}{}}
+		for k, v := range options {
+			c.options[k] = map[string]string{"value": v}
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		config      *VolumeConfig
+		expectMode  string
+		expectUID   string
+		expectGID   string
+		expectACL   string
+		expectError bool
+	}{
+		"defaults": {
+			config:     fakeConfig(nil),
+			expectMode: "0777",
+		},
+		"all set": {
+			config: fakeConfig(map[string]string{
+				KeyDirectoryMode:       "750",
+				KeyDirectoryUID:        "1000",
+				KeyDirectoryGID:        "2000",
+				KeyDirectoryDefaultACL: "u::rwx,group:2000:rwx,o::-",
+			}),
+			expectMode: "0750",
+			expectUID:  "1000",
+			expectGID:  "2000",
+			expectACL:  "u::rwx,group:2000:rwx,o::-",
+		},
+		"setgid mode": {
+			config:     fakeConfig(map[string]string{KeyDirectoryMode: "2770"}),
+			expectMode: "2770",
+		},
+		"invalid mode": {
+			config:      fakeConfig(map[string]string{KeyDirectoryMode: "0778"}),
+			expectError: true,
+		},
+		"symbolic mode": {
+			config:      fakeConfig(map[string]string{KeyDirectoryMode: "u+rwx"}),
+			expectError: true,
+		},
+		"user name": {
+			config:      fakeConfig(map[string]string{KeyDirectoryUID: "root"}),
+			expectError: true,
+		},
+		"negative gid": {
+			config:      fakeConfig(map[string]string{KeyDirectoryGID: "-1"}),
+			expectError: true,
+		},
+		"invalid acl": {
+			config:      fakeConfig(map[string]string{KeyDirectoryDefaultACL: "g::rwx; rm -rf /"}),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			mode, modeErr := v.config.GetDirectoryMode()
+			uid, gid, ownerErr := v.config.GetDirectoryOwner()
+			acl, aclErr := v.config.GetDirectoryDefaultACL()
+			if v.expectError {
+				if modeErr == nil && ownerErr == nil && aclErr == nil {
+					t.Fatalf("expected error, but got none")
+				}
+				return
+			}
+			for _, err := range []error{modeErr, ownerErr, aclErr} {
+				if err != nil {
+					t.Fatalf("expected error to be nil, but got %v", err)
+				}
+			}
+			if mode != v.expectMode || uid != v.expectUID || gid != v.expectGID || acl != v.expectACL {
+				t.Errorf("expected %v %v %v %v, but got %v %v %v %v",
+					v.expectMode, v.expectUID, v.expectGID, v.expectACL, mode, uid, gid, acl)
+			}
+		})
+	}
+}
+
+func TestGetSELinuxContext(t *testing.T) {
+	fakeConfig := func(enabled, value string) *VolumeConfig {
+		return &VolumeConfig{
+			options: map[string]interface{}{
+				KeySELinux: map[string]string{"enabled": enabled, "value": value},
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		config        *VolumeConfig
+		expectContext string
+		expectError   bool
+	}{
+		"not set": {
+			config: &VolumeConfig{options: map[string]interface{}{}},
+		},
+		"disabled": {
+			config: fakeConfig("false", "system_u:object_r:svirt_sandbox_file_t:s0"),
+		},
+		"default context": {
+			config:        fakeConfig("true", ""),
+			expectContext: "system_u:object_r:container_file_t:s0",
+		},
+		"custom context": {
+			config:        fakeConfig("true", "system_u:object_r:container_file_t:s0:c1,c2"),
+			expectContext: "system_u:object_r:container_file_t:s0:c1,c2",
+		},
+		"invalid context": {
+			config:      fakeConfig("true", "container_file_t; reboot"),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			selinuxContext, err := v.config.GetSELinuxContext()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got context %s", selinuxContext)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if selinuxContext != v.expectContext {
+				t.Errorf("expected context %s, but got %s", v.expectContext, selinuxContext)
+			}
+		})
+	}
+}
+
+func TestGetSeed(t *testing.T) {
+	fakeConfig := func(seed map[string]string, allowedSeedPaths []string) *VolumeConfig {
+		c := &VolumeConfig{
+			configData: map[string]interface{}{},
+			configList: map[string]interface{}{},
+		}
+		if seed != nil {
+			c.configData[KeySeed] = seed
+		}
+		if allowedSeedPaths != nil {
+			c.configList[KeyAllowedSeedPaths] = allowedSeedPaths
+		}
+		return c
+	}
+
+	testCases := map[string]struct {
+		config      *VolumeConfig
+		expectSeed  string
+		expectError bool
+	}{
+		"not set": {
+			config: fakeConfig(nil, nil),
+		},
+		"configmap": {
+			config:     fakeConfig(map[string]string{KeySeedConfigMap: "app-skeleton"}, nil),
+			expectSeed: "configMap/app-skeleton",
+		},
+		"secret": {
+			config:     fakeConfig(map[string]string{KeySeedSecret: "app-credentials"}, nil),
+			expectSeed: "secret/app-credentials",
+		},
+		"configmap and secret": {
+			config:      fakeConfig(map[string]string{KeySeedConfigMap: "app-skeleton", KeySeedSecret: "app-credentials"}, nil),
+			expectError: true,
+		},
+		"tarball under allowed path": {
+			config:     fakeConfig(map[string]string{KeySeedTarball: "/var/openebs/seeds/app.tar.gz"}, []string{"/var/openebs/seeds"}),
+			expectSeed: "tarball//var/openebs/seeds/app.tar.gz",
+		},
+		"tarball without allowed paths": {
+			config:      fakeConfig(map[string]string{KeySeedTarball: "/var/openebs/seeds/app.tar.gz"}, nil),
+			expectError: true,
+		},
+		"tarball outside allowed paths": {
+			config:      fakeConfig(map[string]string{KeySeedTarball: "/etc/app.tar"}, []string{"/var/openebs/seeds"}),
+			expectError: true,
+		},
+		"tarball with traversal": {
+			config:      fakeConfig(map[string]string{KeySeedTarball: "/var/openebs/seeds/../../../etc/app.tar"}, []string{"/var/openebs/seeds"}),
+			expectError: true,
+		},
+		"tarball under root allowed path": {
+			config:      fakeConfig(map[string]string{KeySeedTarball: "/etc/app.tar"}, []string{"/"}),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			seed, err := v.config.GetSeed()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got seed %v", seed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			actualSeed := ""
+			if seed != nil {
+				actualSeed = seed.String()
+			}
+			if actualSeed != v.expectSeed {
+				t.Errorf("expected seed %s, but got %s", v.expectSeed, actualSeed)
+			}
+		})
+	}
+}
+
+func TestGetTrashPolicy(t *testing.T) {
+	fakeConfig := func(enabled string, data map[string]string) *VolumeConfig {
+		return &VolumeConfig{
+			options:    map[string]interface{}{KeyTrash: map[string]string{"enabled": enabled}},
+			configData: map[string]interface{}{KeyTrash: data},
+		}
+	}
+
+	testCases := map[string]struct {
+		config      *VolumeConfig
+		expectTrash *trashPolicy
+		expectError bool
+	}{
+		"not set": {
+			config: &VolumeConfig{options: map[string]interface{}{}, configData: map[string]interface{}{}},
+		},
+		"disabled": {
+			config: fakeConfig("false", map[string]string{KeyTrashRetention: "72h"}),
+		},
+		"default retention": {
+			config:      fakeConfig("true", nil),
+			expectTrash: &trashPolicy{retention: 7 * 24 * time.Hour},
+		},
+		"retention and minAvailable": {
+			config:      fakeConfig("true", map[string]string{KeyTrashRetention: "72h", KeyTrashMinAvailable: "10%"}),
+			expectTrash: &trashPolicy{retention: 72 * time.Hour, minAvailable: "10%"},
+		},
+		"minAvailable quantity": {
+			config:      fakeConfig("true", map[string]string{KeyTrashMinAvailable: "50Gi"}),
+			expectTrash: &trashPolicy{retention: 7 * 24 * time.Hour, minAvailable: "50Gi"},
+		},
+		"invalid retention": {
+			config:      fakeConfig("true", map[string]string{KeyTrashRetention: "3 days"}),
+			expectError: true,
+		},
+		"negative retention": {
+			config:      fakeConfig("true", map[string]string{KeyTrashRetention: "-1h"}),
+			expectError: true,
+		},
+		"invalid minAvailable": {
+			config:      fakeConfig("true", map[string]string{KeyTrashMinAvailable: "110%"}),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			trash, err := v.config.GetTrashPolicy()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got trash policy %v", trash)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if !reflect.DeepEqual(trash, v.expectTrash) {
+				t.Errorf("expected trash policy %v, but got %v", v.expectTrash, trash)
+			}
+		})
+	}
+}
+
+func TestGetWipePolicy(t *testing.T) {
+	testCases := map[string]struct {
+		value        string
+		expectPolicy string
+		expectError  bool
+	}{
+		"not set":  {expectPolicy: WipePolicyNone},
+		"zerofill": {value: "ZeroFill", expectPolicy: WipePolicyZeroFill},
+		"discard":  {value: "Discard", expectPolicy: WipePolicyDiscard},
+		"invalid":  {value: "zero", expectError: true},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			config := &VolumeConfig{options: map[string]interface{}{
+				KeyWipePolicy: map[string]string{"value": v.value},
+			}}
+			policy, err := config.GetWipePolicy()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got wipe policy %v", policy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if policy != v.expectPolicy {
+				t.Errorf("expected wipe policy %s, but got %s", v.expectPolicy, policy)
+			}
+		})
+	}
+}
+
+func TestGetNodeGonePolicy(t *testing.T) {
+	fakeConfig := func(value string, data map[string]string) *VolumeConfig {
+		return &VolumeConfig{
+			options:    map[string]interface{}{KeyNodeGonePolicy: map[string]string{"value": value}},
+			configData: map[string]interface{}{KeyNodeGonePolicy: data},
+		}
+	}
+
+	testCases := map[string]struct {
+		config            *VolumeConfig
+		expectPolicy      string
+		expectGracePeriod time.Duration
+		expectError       bool
+	}{
+		"not set": {
+			config:       fakeConfig("", nil),
+			expectPolicy: NodeGonePolicyFail,
+		},
+		"wait": {
+			config:       fakeConfig("Wait", nil),
+			expectPolicy: NodeGonePolicyWait,
+		},
+		"forget with default grace period": {
+			config:            fakeConfig("Forget", nil),
+			expectPolicy:      NodeGonePolicyForget,
+			expectGracePeriod: 24 * time.Hour,
+		},
+		"forget with grace period": {
+			config:            fakeConfig("Forget", map[string]string{KeyNodeGoneGracePeriod: "30m"}),
+			expectPolicy:      NodeGonePolicyForget,
+			expectGracePeriod: 30 * time.Minute,
+		},
+		"invalid grace period": {
+			config:      fakeConfig("Forget", map[string]string{KeyNodeGoneGracePeriod: "1 day"}),
+			expectError: true,
+		},
+		"invalid policy": {
+			config:      fakeConfig("Delete", nil),
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			policy, gracePeriod, err := v.config.GetNodeGonePolicy()
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got node gone policy %v", policy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if policy != v.expectPolicy || gracePeriod != v.expectGracePeriod {
+				t.Errorf("expected node gone policy %v with grace period %v, but got %v with %v",
+					v.expectPolicy, v.expectGracePeriod, policy, gracePeriod)
+			}
+		})
+	}
+}
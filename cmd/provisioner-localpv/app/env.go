@@ -17,7 +17,10 @@ limitations under the License.
 package app
 
 import (
+	"time"
+
 	menv "github.com/openebs/maya/pkg/env/v1alpha1"
+	"k8s.io/klog/v2"
 )
 
 //This file defines the environement variable names that are specific
@@ -42,11 +45,64 @@ const (
 	// ProvisionerImagePullSecrets is the environment variable that provides the
 	// init pod to use as authentication when pulling helper image, it is used in the scene where authentication is required
 	ProvisionerImagePullSecrets menv.ENVKey = "OPENEBS_IO_IMAGE_PULL_SECRETS"
+
+	// ProvisionerEnableCapacityTracking is the environment variable that
+	// enables publishing the available capacity of the hostpath
+	// StorageClasses on each node as CSIStorageCapacity objects.
+	ProvisionerEnableCapacityTracking menv.ENVKey = "OPENEBS_IO_ENABLE_CAPACITY_TRACKING"
+
+	// ProvisionerCapacityPollInterval is the environment variable that
+	// provides the interval at which the available capacity is measured.
+	ProvisionerCapacityPollInterval menv.ENVKey = "OPENEBS_IO_CAPACITY_POLL_INTERVAL"
+
+	// ProvisionerTrashPurgeInterval is the environment variable that
+	// provides the interval at which the trashed hostpath volumes are
+	// checked for purging.
+	ProvisionerTrashPurgeInterval menv.ENVKey = "OPENEBS_IO_TRASH_PURGE_INTERVAL"
+
+	// ProvisionerHelperJobTTL is the environment variable that provides
+	// the time for which a finished helper job is kept.
+	ProvisionerHelperJobTTL menv.ENVKey = "OPENEBS_IO_HELPER_JOB_TTL"
+
+	// ProvisionerHelperPodTemplate is the environment variable that
+	// provides the name of the ConfigMap of the default helper pod
+	// template, in the namespace of the provisioner.
+	ProvisionerHelperPodTemplate menv.ENVKey = "OPENEBS_IO_HELPER_POD_TEMPLATE"
+
+	// ProvisionerHelperNamespace is the environment variable that
+	// provides the namespace of the helper jobs. Defaults to the
+	// namespace of the provisioner.
+	ProvisionerHelperNamespace menv.ENVKey = "OPENEBS_IO_HELPER_NAMESPACE"
+
+	// ProvisionerHelperPodPrivileged is the environment variable that
+	// launches all the helper pods in privileged mode.
+	ProvisionerHelperPodPrivileged menv.ENVKey = "OPENEBS_IO_HELPER_POD_PRIVILEGED"
+
+	// ProvisionerMetricsAddress is the environment variable that provides
+	// the address at which the metrics are served. The metrics server is
+	// disabled if it is set to an empty value.
+	ProvisionerMetricsAddress menv.ENVKey = "OPENEBS_IO_METRICS_ADDRESS"
+
+	// ProvisionerHealthProbeAddress is the environment variable that
+	// provides the address at which the liveness and readiness endpoints
+	// are served. They are disabled if it is set to an empty value.
+	ProvisionerHealthProbeAddress menv.ENVKey = "OPENEBS_IO_HEALTH_PROBE_ADDRESS"
+
+	// ProvisionerShutdownTimeout is the environment variable that
+	// provides the time for which the provisioner waits, on shutdown, for
+	// the volumes being provisioned or deleted.
+	ProvisionerShutdownTimeout menv.ENVKey = "OPENEBS_IO_SHUTDOWN_TIMEOUT"
 )
 
 var (
-	defaultHelperImage = "openebs/linux-utils:latest"
-	defaultBasePath    = "/var/openebs/local"
+	defaultHelperImage          = "openebs/linux-utils:latest"
+	defaultBasePath             = "/var/openebs/local"
+	defaultCapacityPollInterval = 5 * time.Minute
+	defaultTrashPurgeInterval   = 10 * time.Minute
+	defaultHelperJobTTL         = time.Hour
+	defaultMetricsAddress       = ":9500"
+	defaultHealthProbeAddress   = ":9501"
+	defaultShutdownTimeout      = 2 * time.Minute
 )
 
 func getOpenEBSNamespace() string {
@@ -66,3 +122,66 @@ func getOpenEBSServiceAccountName() string {
 func getOpenEBSImagePullSecrets() string {
 	return menv.Get(ProvisionerImagePullSecrets)
 }
+
+func isCapacityTrackingEnabled() bool {
+	return menv.Truthy(ProvisionerEnableCapacityTracking)
+}
+
+func getCapacityPollInterval() time.Duration {
+	return getDurationOrDefault(ProvisionerCapacityPollInterval, defaultCapacityPollInterval)
+}
+
+func getTrashPurgeInterval() time.Duration {
+	return getDurationOrDefault(ProvisionerTrashPurgeInterval, defaultTrashPurgeInterval)
+}
+
+func getHelperPodTemplateName() string {
+	return menv.Get(ProvisionerHelperPodTemplate)
+}
+
+func getHelperNamespace() string {
+	return menv.Get(ProvisionerHelperNamespace)
+}
+
+func isHelperPodPrivileged() bool {
+	return menv.Truthy(ProvisionerHelperPodPrivileged)
+}
+
+func getMetricsAddress() string {
+	address, present := menv.Lookup(ProvisionerMetricsAddress)
+	if !present {
+		return defaultMetricsAddress
+	}
+	return address
+}
+
+func getHealthProbeAddress() string {
+	address, present := menv.Lookup(ProvisionerHealthProbeAddress)
+	if !present {
+		return defaultHealthProbeAddress
+	}
+	return address
+}
+
+func getShutdownTimeout() time.Duration {
+	return getDurationOrDefault(ProvisionerShutdownTimeout, defaultShutdownTimeout)
+}
+
+func getHelperJobTTL() time.Duration {
+	return getDurationOrDefault(ProvisionerHelperJobTTL, defaultHelperJobTTL)
+}
+
+// getDurationOrDefault returns the positive duration set in the
+// environment variable, or else the default duration.
+func getDurationOrDefault(key menv.ENVKey, defaultValue time.Duration) time.Duration {
+	value := menv.Get(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		klog.Warningf("Invalid %v %q, using %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+// The event codes of the alertlog. They are also the reasons of the
+// events emitted on the PVCs and PVs, so that an alert and the event of
+// the same step can be matched.
+const (
+	eventCodeProvisionSuccess = "local.pv.provision.success"
+	eventCodeProvisionFailure = "local.pv.provision.failure"
+	eventCodeDeleteSuccess    = "local.pv.delete.success"
+	eventCodeDeleteFailure    = "local.pv.delete.failure"
+	eventCodeHelperLaunch     = "local.pv.helper.launch"
+	eventCodeHelperFailure    = "local.pv.helper.failure"
+	eventCodeInitSuccess      = "local.pv.init.success"
+	eventCodeQuotaSuccess     = "local.pv.quota.success"
+	eventCodeCloneSuccess     = "local.pv.clone.success"
+	eventCodeCleanupSuccess   = "local.pv.cleanup.success"
+	eventCodeResizeSuccess    = "local.pv.resize.success"
+	eventCodeResizeFailure    = "local.pv.resize.failure"
+	eventCodeSnapshotSuccess  = "local.pv.snapshot.success"
+	eventCodeSnapshotFailure  = "local.pv.snapshot.failure"
+	eventCodeWipeStarted      = "local.pv.wipe.started"
+	eventCodeWipeProgress     = "local.pv.wipe.progress"
+	eventCodeWipeSuccess      = "local.pv.wipe.success"
+	eventCodeWipeFailure      = "local.pv.wipe.failure"
+	eventCodeNodeGone         = "local.pv.nodegone"
+)
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestHelperLaunchEvent(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvcName", Namespace: "app", UID: "pvc-uid"},
+	}
+	testCases := map[string]struct {
+		eventObject  *corev1.PersistentVolumeClaim
+		expectEvents []string
+	}{
+		"pvc": {
+			eventObject:  pvc,
+			expectEvents: []string{"Normal " + eventCodeHelperLaunch + " Launched init helper job openebs/init-pvc-0001"},
+		},
+		"no event object": {},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			p := &Provisioner{
+				kubeClient:  fake.NewSimpleClientset(),
+				namespace:   "openebs",
+				helperImage: "openebs/linux-utils:latest",
+				recorder:    recorder,
+			}
+			pOpts := &HelperPodOptions{
+				name:               "pvc-0001",
+				pvName:             "pvc-0001",
+				serviceAccountName: "openebs-localpv",
+				nodeAffinityLabels: map[string]string{"kubernetes.io/hostname": "node-1"},
+				cmdsForPath:        []string{"mkdir", "-p"},
+			}
+			if v.eventObject != nil {
+				pOpts.eventObject = v.eventObject
+			}
+			config := podConfig{podName: "init", parentDir: "/var/openebs/local", pOpts: pOpts}
+			if _, err := p.launchPod(context.Background(), config); err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if len(events) != len(v.expectEvents) {
+				t.Fatalf("expected events %v, but got %v", v.expectEvents, events)
+			}
+			for i := range events {
+				if events[i] != v.expectEvents[i] {
+					t.Errorf("expected event %q, but got %q", v.expectEvents[i], events[i])
+				}
+			}
+		})
+	}
+}
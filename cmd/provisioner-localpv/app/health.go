@@ -0,0 +1,284 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// healthzPath and readyzPath are the paths of the liveness and
+	// readiness endpoints.
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	// apiServerCheckTimeout is the time within which the API server
+	// must respond to the readiness check.
+	apiServerCheckTimeout = 5 * time.Second
+
+	// startupTimeout is the time within which the provision controller
+	// must start running, if leader election is disabled.
+	startupTimeout = 2 * time.Minute
+)
+
+var (
+	// provisionStallTimeout, deleteStallTimeout and wipeStallTimeout are
+	// the times after which a Provision, a Delete, or a Delete that wipes
+	// the volume is reported as stalled. They are well past the timeouts
+	// of the helper pods of the operation, so that
+	// only an operation that is stuck, e.g. on an API call that never
+	// returns, fails the liveness check.
+	provisionStallTimeout = time.Duration(CloneTimeoutCounts+5*CmdTimeoutCounts) * time.Second
+	deleteStallTimeout    = time.Duration(6*CmdTimeoutCounts) * time.Second
+	wipeStallTimeout      = time.Duration(WipeTimeoutCounts+5*CmdTimeoutCounts) * time.Second
+
+	// snapshotStallTimeout and helperStallTimeout are the times after
+	// which an operation of the other controllers is reported as
+	// stalled, so that a controller loop that is stuck fails the
+	// liveness check. A snapshot copies the volume like a clone, and
+	// the other operations, e.g. an expansion or the measurement of
+	// the capacity, run a single helper pod.
+	snapshotStallTimeout = provisionStallTimeout
+	helperStallTimeout   = time.Duration(6*CmdTimeoutCounts) * time.Second
+)
+
+// operationTracker tracks the Provision and Delete operations, and the
+// operations of the other controllers, in progress, to detect an
+// operation that has stalled, and to wait for the operations in
+// progress on shutdown.
+type operationTracker struct {
+	lock       sync.Mutex
+	nextID     uint64
+	operations map[uint64]trackedOperation
+	// draining is set on shutdown, after which no new operation is
+	// started. idle is closed once the last operation is done.
+	draining bool
+	idle     chan struct{}
+}
+
+type trackedOperation struct {
+	name     string
+	deadline time.Time
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{operations: map[uint64]trackedOperation{}}
+}
+
+// track tracks the operation until the returned func is called. The
+// operation is stalled if it is not done within the timeout. An error
+// is returned if the provisioner is shutting down, so that the
+// operation is retried later, e.g. by the next leader. A nil tracker
+// does not track the operation.
+func (t *operationTracker) track(name string, timeout time.Duration) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.draining {
+		return nil, errors.Errorf("provisioner is shutting down, %v will be retried", name)
+	}
+	id := t.nextID
+	t.nextID++
+	t.operations[id] = trackedOperation{name: name, deadline: time.Now().Add(timeout)}
+	return func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		delete(t.operations, id)
+		if t.idle != nil && len(t.operations) == 0 {
+			close(t.idle)
+			t.idle = nil
+		}
+	}, nil
+}
+
+// trackOperation runs an operation of a controller other than the
+// provision controller, tracked till it returns. An error is returned
+// without running the operation if the provisioner is shutting down.
+func (p *Provisioner) trackOperation(name string, timeout time.Duration, operation func() error) error {
+	done, err := p.operations.track(name, timeout)
+	if err != nil {
+		return err
+	}
+	defer done()
+	return operation()
+}
+
+// drain stops new operations from being started, and waits up to the
+// timeout for the operations in progress to be done. It returns the
+// names of the operations still in progress after the timeout.
+func (t *operationTracker) drain(timeout time.Duration) []string {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	t.draining = true
+	if len(t.operations) == 0 {
+		t.lock.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-time.After(timeout):
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var names []string
+	for _, operation := range t.operations {
+		names = append(names, operation.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDraining returns true once the provisioner is shutting down.
+func (t *operationTracker) isDraining() bool {
+	if t == nil {
+		return false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.draining
+}
+
+// stalled returns the names of the operations past their deadline.
+func (t *operationTracker) stalled(now time.Time) []string {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var names []string
+	for _, operation := range t.operations {
+		if now.After(operation.deadline) {
+			names = append(names, operation.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// healthChecker serves the liveness and readiness of the provisioner.
+type healthChecker struct {
+	kubeClient clientset.Interface
+	// leaderElection is true if the provision controller runs only
+	// while it holds the leader lease.
+	leaderElection bool
+	// hasRun returns true once the provision controller runs, i.e.
+	// after it has acquired the leader lease, if leader election is
+	// enabled. The provisioner exits if it loses the lease.
+	hasRun func() bool
+	// operations has the operations of the provision controller and
+	// of the other controllers, which are stalled if a controller
+	// loop is stuck.
+	operations *operationTracker
+	startTime  time.Time
+}
+
+func newHealthChecker(kubeClient clientset.Interface, leaderElection bool, hasRun func() bool, operations *operationTracker) *healthChecker {
+	return &healthChecker{
+		kubeClient:     kubeClient,
+		leaderElection: leaderElection,
+		hasRun:         hasRun,
+		operations:     operations,
+		startTime:      time.Now(),
+	}
+}
+
+// checkLive returns an error if a controller has stalled, i.e. a
+// Provision, a Delete or an operation of the other controllers is
+// stuck, or the provision controller has not started running without
+// leader election.
+func (h *healthChecker) checkLive() error {
+	if stalled := h.operations.stalled(time.Now()); len(stalled) > 0 {
+		return errors.Errorf("stalled operations: %v", strings.Join(stalled, ", "))
+	}
+	if !h.leaderElection && !h.hasRun() && time.Since(h.startTime) > startupTimeout {
+		return errors.Errorf("provision controller has not started within %v", startupTimeout)
+	}
+	return nil
+}
+
+// checkReady returns an error if the API server cannot be reached, the
+// provisioner is shutting down, or the provision controller is not
+// running without leader election. With leader election, a replica
+// waiting for the leader lease is ready, so that it can take over; the
+// leader is reported by the leader metric.
+func (h *healthChecker) checkReady(ctx context.Context) error {
+	if h.operations.isDraining() {
+		return errors.New("shutting down")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, apiServerCheckTimeout)
+	defer cancel()
+
+	apiErr := make(chan error, 1)
+	go func() {
+		_, err := h.kubeClient.Discovery().ServerVersion()
+		apiErr <- err
+	}()
+	select {
+	case err := <-apiErr:
+		if err != nil {
+			return errors.Wrap(err, "failed to reach API server")
+		}
+	case <-ctx.Done():
+		return errors.Errorf("API server did not respond within %v", apiServerCheckTimeout)
+	}
+
+	if !h.leaderElection && !h.hasRun() {
+		return errors.New("provision controller not running")
+	}
+	return nil
+}
+
+// register serves the liveness and readiness endpoints on the mux.
+func (h *healthChecker) register(mux *http.ServeMux) {
+	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, h.checkLive())
+	})
+	mux.HandleFunc(readyzPath, func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, h.checkReady(r.Context()))
+	})
+}
+
+func writeHealth(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, err.Error())
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOperationTracker(t *testing.T) {
+	tracker := newOperationTracker()
+	doneStalled, _ := tracker.track("provision pvc-0001", -time.Second)
+	doneRunning, _ := tracker.track("delete pvc-0002", time.Hour)
+	defer doneRunning()
+
+	if stalled := tracker.stalled(time.Now()); !reflect.DeepEqual(stalled, []string{"provision pvc-0001"}) {
+		t.Errorf("expected stalled provision, but got %v", stalled)
+	}
+	doneStalled()
+	if stalled := tracker.stalled(time.Now()); len(stalled) != 0 {
+		t.Errorf("expected no stalled operations, but got %v", stalled)
+	}
+
+	var nilTracker *operationTracker
+	done, err := nilTracker.track("provision pvc-0003", -time.Second)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	done()
+	if stalled := nilTracker.stalled(time.Now()); len(stalled) != 0 {
+		t.Errorf("expected no stalled operations, but got %v", stalled)
+	}
+}
+
+func TestTrackOperation(t *testing.T) {
+	p := &Provisioner{operations: newOperationTracker()}
+
+	err := p.trackOperation("resize default/pvc-0001", -time.Second, func() error {
+		if stalled := p.operations.stalled(time.Now()); !reflect.DeepEqual(stalled, []string{"resize default/pvc-0001"}) {
+			t.Errorf("expected stalled resize, but got %v", stalled)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	if stalled := p.operations.stalled(time.Now()); len(stalled) != 0 {
+		t.Errorf("expected no stalled operations, but got %v", stalled)
+	}
+
+	p.operations.drain(0)
+	ran := false
+	err = p.trackOperation("resize default/pvc-0001", time.Hour, func() error {
+		ran = true
+		return nil
+	})
+	if err == nil || ran {
+		t.Errorf("expected operation not to run on shutdown, but got error %v and ran %v", err, ran)
+	}
+}
+
+func TestHealthChecker(t *testing.T) {
+	testCases := map[string]struct {
+		leaderElection bool
+		hasRun         bool
+		stalled        bool
+		draining       bool
+		started        time.Time
+		expectLive     bool
+		expectReady    bool
+	}{
+		"leader": {
+			leaderElection: true,
+			hasRun:         true,
+			expectLive:     true,
+			expectReady:    true,
+		},
+		"standby replica": {
+			leaderElection: true,
+			started:        time.Now().Add(-time.Hour),
+			expectLive:     true,
+			expectReady:    true,
+		},
+		"starting without leader election": {
+			expectLive: true,
+		},
+		"not started without leader election": {
+			started: time.Now().Add(-time.Hour),
+		},
+		"stalled operation": {
+			leaderElection: true,
+			hasRun:         true,
+			stalled:        true,
+			expectReady:    true,
+		},
+		"shutting down": {
+			leaderElection: true,
+			hasRun:         true,
+			draining:       true,
+			expectLive:     true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			operations := newOperationTracker()
+			if v.stalled {
+				done, _ := operations.track("provision pvc-0001", -time.Second)
+				defer done()
+			}
+			if v.draining {
+				operations.drain(0)
+			}
+			health := newHealthChecker(fake.NewSimpleClientset(), v.leaderElection,
+				func() bool { return v.hasRun }, operations)
+			if !v.started.IsZero() {
+				health.startTime = v.started
+			}
+
+			mux := http.NewServeMux()
+			health.register(mux)
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			for path, expectOK := range map[string]bool{healthzPath: v.expectLive, readyzPath: v.expectReady} {
+				resp, err := http.Get(server.URL + path)
+				if err != nil {
+					t.Fatalf("expected error to be nil, but got %v", err)
+				}
+				resp.Body.Close()
+				if (resp.StatusCode == http.StatusOK) != expectOK {
+					t.Errorf("expected %v ok %v, but got status %v", path, expectOK, resp.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckReadyAPIServer(t *testing.T) {
+	health := newHealthChecker(fake.NewSimpleClientset(), false, func() bool { return true }, nil)
+	if err := health.checkReady(context.Background()); err != nil {
+		t.Errorf("expected error to be nil, but got %v", err)
+	}
+}
+
+func TestNewServeMuxes(t *testing.T) {
+	health := newHealthChecker(fake.NewSimpleClientset(), false, func() bool { return true }, nil)
+	testCases := map[string]struct {
+		metricsAddress  string
+		healthAddress   string
+		expectAddresses []string
+	}{
+		"separate":         {metricsAddress: ":9500", healthAddress: ":9501", expectAddresses: []string{":9500", ":9501"}},
+		"shared":           {metricsAddress: ":9500", healthAddress: ":9500", expectAddresses: []string{":9500"}},
+		"metrics disabled": {healthAddress: ":9501", expectAddresses: []string{":9501"}},
+		"all disabled":     {},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			muxes := newServeMuxes(v.metricsAddress, v.healthAddress, health)
+			if len(muxes) != len(v.expectAddresses) {
+				t.Fatalf("expected servers at %v, but got %v", v.expectAddresses, muxes)
+			}
+			for _, address := range v.expectAddresses {
+				if _, ok := muxes[address]; !ok {
+					t.Errorf("expected server at %v", address)
+				}
+			}
+		})
+	}
+}
+
+func TestOperationTrackerDrain(t *testing.T) {
+	testCases := map[string]struct {
+		finishInTime  bool
+		expectPending []string
+	}{
+		"operations done": {
+			finishInTime: true,
+		},
+		"timeout": {
+			expectPending: []string{"provision pvc-0001"},
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			tracker := newOperationTracker()
+			done, err := tracker.track("provision pvc-0001", time.Hour)
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if v.finishInTime {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					done()
+				}()
+			}
+
+			pending := tracker.drain(500 * time.Millisecond)
+			if !reflect.DeepEqual(pending, v.expectPending) {
+				t.Errorf("expected pending operations %v, but got %v", v.expectPending, pending)
+			}
+			if _, err := tracker.track("delete pvc-0002", time.Hour); err == nil {
+				t.Errorf("expected error for an operation started while draining")
+			}
+			if !tracker.isDraining() {
+				t.Errorf("expected tracker to be draining")
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"context"
 	"time"
 
+	ndmapis "github.com/openebs/maya/pkg/apis/openebs.io/ndm/v1alpha1"
 	blockdevice "github.com/openebs/maya/pkg/blockdevice/v1alpha2"
 	blockdeviceclaim "github.com/openebs/maya/pkg/blockdeviceclaim/v1alpha1"
 	"github.com/openebs/maya/pkg/util"
@@ -38,9 +39,48 @@ const (
 	LocalPVFinalizer = "local.openebs.io/finalizer"
 )
 
-// WaitForBDTimeoutCounts specifies the duration to wait for BDC to be associated with a BD
-// The duration is the value specified here multiplied by 5
-var WaitForBDTimeoutCounts int
+var (
+	// WaitForBDTimeoutCounts specifies the duration to wait for BDC to be associated with a BD
+	// The duration is the value specified here multiplied by WaitForBDInterval
+	WaitForBDTimeoutCounts = 12
+
+	// WaitForBDInterval is the interval at which the BDC is checked
+	// for an associated BD
+	WaitForBDInterval = 5 * time.Second
+)
+
+// ndmClient is used to access the BlockDevice and BlockDeviceClaim
+// objects managed by NDM.
+type ndmClient interface {
+	GetBlockDeviceClaim(ctx context.Context, namespace, name string) (*ndmapis.BlockDeviceClaim, error)
+	CreateBlockDeviceClaim(ctx context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error)
+	UpdateBlockDeviceClaim(ctx context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error)
+	DeleteBlockDeviceClaim(ctx context.Context, namespace, name string) error
+	GetBlockDevice(ctx context.Context, namespace, name string) (*ndmapis.BlockDevice, error)
+}
+
+// kubeNDMClient implements ndmClient using the NDM kubernetes clients.
+type kubeNDMClient struct{}
+
+func (kubeNDMClient) GetBlockDeviceClaim(ctx context.Context, namespace, name string) (*ndmapis.BlockDeviceClaim, error) {
+	return blockdeviceclaim.NewKubeClient().WithNamespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (kubeNDMClient) CreateBlockDeviceClaim(ctx context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error) {
+	return blockdeviceclaim.NewKubeClient().WithNamespace(bdc.Namespace).Create(ctx, bdc)
+}
+
+func (kubeNDMClient) UpdateBlockDeviceClaim(ctx context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error) {
+	return blockdeviceclaim.NewKubeClient().WithNamespace(bdc.Namespace).Update(ctx, bdc)
+}
+
+func (kubeNDMClient) DeleteBlockDeviceClaim(ctx context.Context, namespace, name string) error {
+	return blockdeviceclaim.NewKubeClient().WithNamespace(namespace).Delete(ctx, name, &metav1.DeleteOptions{})
+}
+
+func (kubeNDMClient) GetBlockDevice(_ context.Context, namespace, name string) (*ndmapis.BlockDevice, error) {
+	return blockdevice.NewKubeClient().WithNamespace(namespace).Get(name, metav1.GetOptions{})
+}
 
 // HelperBlockDeviceOptions contains the options that
 // will launch a BDC on a specific node (nodeHostname)
@@ -111,9 +151,7 @@ func (p *Provisioner) createBlockDeviceClaim(ctx context.Context, blkDevOpts *He
 	//Check if the BDC is already created. This can happen
 	//if the previous reconciliation of PVC-PV, resulted in
 	//creating a BDC, but BD was not yet available for 60+ seconds
-	_, err := blockdeviceclaim.NewKubeClient().
-		WithNamespace(p.namespace).
-		Get(ctx, bdcName, metav1.GetOptions{})
+	_, err := p.ndm.GetBlockDeviceClaim(ctx, p.namespace, bdcName)
 	if err == nil {
 		blkDevOpts.bdcName = bdcName
 		klog.Infof("Volume %v has been initialized with BDC:%v", blkDevOpts.name, bdcName)
@@ -140,9 +178,7 @@ func (p *Provisioner) createBlockDeviceClaim(ctx context.Context, blkDevOpts *He
 		return errors.Wrapf(err, "unable to build BDC")
 	}
 
-	_, err = blockdeviceclaim.NewKubeClient().
-		WithNamespace(p.namespace).
-		Create(ctx, bdcObj.Object)
+	_, err = p.ndm.CreateBlockDeviceClaim(ctx, bdcObj.Object)
 
 	if err != nil {
 		//TODO : Need to relook at this error
@@ -173,9 +209,7 @@ func (p *Provisioner) getBlockDevicePath(ctx context.Context, blkDevOpts *Helper
 	//Check if the BDC is created
 	for i := 0; i < WaitForBDTimeoutCounts; i++ {
 
-		bdc, err := blockdeviceclaim.NewKubeClient().
-			WithNamespace(p.namespace).
-			Get(ctx, blkDevOpts.bdcName, metav1.GetOptions{})
+		bdc, err := p.ndm.GetBlockDeviceClaim(ctx, p.namespace, blkDevOpts.bdcName)
 		if err != nil {
 			//TODO : Need to relook at this error
 			//If the error is about BDC being already present, then return nil
@@ -185,7 +219,7 @@ func (p *Provisioner) getBlockDevicePath(ctx context.Context, blkDevOpts *Helper
 		bdName = bdc.Spec.BlockDeviceName
 		//Check if the BDC is associated with a BD
 		if bdName == "" {
-			time.Sleep(5 * time.Second)
+			time.Sleep(WaitForBDInterval)
 		} else {
 			break
 		}
@@ -203,9 +237,7 @@ func (p *Provisioner) getBlockDevicePath(ctx context.Context, blkDevOpts *Helper
 	}
 
 	//Get the BD Path.
-	bd, err := blockdevice.NewKubeClient().
-		WithNamespace(p.namespace).
-		Get(bdName, metav1.GetOptions{})
+	bd, err := p.ndm.GetBlockDevice(ctx, p.namespace, bdName)
 	if err != nil {
 		//TODO : Need to relook at this error
 		//If the error is about BDC being already present, then return nil
@@ -244,9 +276,7 @@ func (p *Provisioner) deleteBlockDeviceClaim(ctx context.Context, blkDevOpts *He
 		return errors.Errorf("unable to remove finalizer on BDC %v : %v", blkDevOpts.name, err)
 	}
 
-	err = blockdeviceclaim.NewKubeClient().
-		WithNamespace(p.namespace).
-		Delete(ctx, blkDevOpts.bdcName, &metav1.DeleteOptions{})
+	err = p.ndm.DeleteBlockDeviceClaim(ctx, p.namespace, blkDevOpts.bdcName)
 
 	if err != nil {
 		//TODO : Need to relook at this error
@@ -258,9 +288,7 @@ func (p *Provisioner) deleteBlockDeviceClaim(ctx context.Context, blkDevOpts *He
 func (p *Provisioner) removeFinalizer(ctx context.Context, blkDevOpts *HelperBlockDeviceOptions) error {
 	klog.V(4).Info("removing local-pv finalizer on the BDC")
 
-	bdc, err := blockdeviceclaim.NewKubeClient().
-		WithNamespace(p.namespace).
-		Get(ctx, blkDevOpts.bdcName, metav1.GetOptions{})
+	bdc, err := p.ndm.GetBlockDeviceClaim(ctx, p.namespace, blkDevOpts.bdcName)
 	if err != nil {
 		return errors.Errorf("unable to get BDC %s for removing finalizer", blkDevOpts.bdcName)
 	}
@@ -269,9 +297,7 @@ func (p *Provisioner) removeFinalizer(ctx context.Context, blkDevOpts *HelperBlo
 	bdc.Finalizers = util.RemoveString(bdc.Finalizers, LocalPVFinalizer)
 
 	// udpate the BDC with the new finalizer array
-	_, err = blockdeviceclaim.NewKubeClient().
-		WithNamespace(p.namespace).
-		Update(ctx, bdc)
+	_, err = p.ndm.UpdateBlockDeviceClaim(ctx, bdc)
 
 	return err
 }
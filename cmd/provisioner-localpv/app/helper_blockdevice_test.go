@@ -0,0 +1,252 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ndmapis "github.com/openebs/maya/pkg/apis/openebs.io/ndm/v1alpha1"
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+)
+
+// fakeNDMClient is an in-memory ndmClient. If bindTo is set, new
+// BlockDeviceClaims are bound to the named BlockDevice, as done by NDM.
+type fakeNDMClient struct {
+	bdcs   map[string]*ndmapis.BlockDeviceClaim
+	bds    map[string]*ndmapis.BlockDevice
+	bindTo string
+}
+
+func newFakeNDMClient(bindTo string, bds ...*ndmapis.BlockDevice) *fakeNDMClient {
+	c := &fakeNDMClient{
+		bdcs:   map[string]*ndmapis.BlockDeviceClaim{},
+		bds:    map[string]*ndmapis.BlockDevice{},
+		bindTo: bindTo,
+	}
+	for _, bd := range bds {
+		c.bds[bd.Name] = bd
+	}
+	return c
+}
+
+func (c *fakeNDMClient) GetBlockDeviceClaim(_ context.Context, _, name string) (*ndmapis.BlockDeviceClaim, error) {
+	bdc, ok := c.bdcs[name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "blockdeviceclaims"}, name)
+	}
+	return bdc.DeepCopy(), nil
+}
+
+func (c *fakeNDMClient) CreateBlockDeviceClaim(_ context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error) {
+	if _, ok := c.bdcs[bdc.Name]; ok {
+		return nil, k8serrors.NewAlreadyExists(schema.GroupResource{Resource: "blockdeviceclaims"}, bdc.Name)
+	}
+	bdc = bdc.DeepCopy()
+	bdc.Spec.BlockDeviceName = c.bindTo
+	c.bdcs[bdc.Name] = bdc
+	return bdc.DeepCopy(), nil
+}
+
+func (c *fakeNDMClient) UpdateBlockDeviceClaim(_ context.Context, bdc *ndmapis.BlockDeviceClaim) (*ndmapis.BlockDeviceClaim, error) {
+	if _, ok := c.bdcs[bdc.Name]; !ok {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "blockdeviceclaims"}, bdc.Name)
+	}
+	c.bdcs[bdc.Name] = bdc.DeepCopy()
+	return bdc.DeepCopy(), nil
+}
+
+func (c *fakeNDMClient) DeleteBlockDeviceClaim(_ context.Context, _, name string) error {
+	bdc, ok := c.bdcs[name]
+	if !ok {
+		return k8serrors.NewNotFound(schema.GroupResource{Resource: "blockdeviceclaims"}, name)
+	}
+	// A BDC with finalizers is only marked for deletion.
+	if len(bdc.Finalizers) > 0 {
+		now := metav1.Now()
+		bdc.DeletionTimestamp = &now
+		return nil
+	}
+	delete(c.bdcs, name)
+	return nil
+}
+
+func (c *fakeNDMClient) GetBlockDevice(_ context.Context, _, name string) (*ndmapis.BlockDevice, error) {
+	bd, ok := c.bds[name]
+	if !ok {
+		return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "blockdevices"}, name)
+	}
+	return bd.DeepCopy(), nil
+}
+
+func fakeBlockDevice(name, mountpoint string) *ndmapis.BlockDevice {
+	bd := &ndmapis.BlockDevice{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	bd.Spec.Path = "/dev/sdb"
+	bd.Spec.FileSystem.Mountpoint = mountpoint
+	bd.Spec.DevLinks = []ndmapis.DeviceDevLink{
+		{Kind: "by-path", Links: []string{"/dev/disk/by-path/pci-0000:00:10.0-scsi-0:0:1:0"}},
+		{Kind: "by-id", Links: []string{"/dev/disk/by-id/scsi-0QEMU_HARDDISK_1"}},
+	}
+	return bd
+}
+
+func fakeDeviceProvisionOptions(volumeMode v1.PersistentVolumeMode) pvController.ProvisionOptions {
+	reclaimPolicy := v1.PersistentVolumeReclaimDelete
+	return pvController.ProvisionOptions{
+		PVName: "pvName",
+		PVC: &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvcName", Namespace: "default"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				VolumeMode:  &volumeMode,
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+		StorageClass: &storagev1.StorageClass{ReclaimPolicy: &reclaimPolicy},
+		SelectedNode: fakeNode("node-1"),
+	}
+}
+
+func fakeDeviceConfig() *VolumeConfig {
+	return &VolumeConfig{
+		pvName: "pvName",
+		options: map[string]interface{}{
+			KeyPVStorageType: map[string]string{"value": "device"},
+		},
+		configData: map[string]interface{}{
+			KeyBlockDeviceSelectors: map[string]string{"ndm.io/fsType": "ext4"},
+		},
+		configList: map[string]interface{}{},
+	}
+}
+
+func TestProvisionBlockDevice(t *testing.T) {
+	WaitForBDInterval = time.Millisecond
+	defer func() { WaitForBDInterval = 5 * time.Second }()
+
+	testCases := map[string]struct {
+		ndm        *fakeNDMClient
+		volumeMode v1.PersistentVolumeMode
+		expectPath string
+		expectErr  bool
+	}{
+		"device with filesystem": {
+			ndm:        newFakeNDMClient("bd-1", fakeBlockDevice("bd-1", "/mnt/bd-1")),
+			volumeMode: v1.PersistentVolumeFilesystem,
+			expectPath: "/mnt/bd-1",
+		},
+		"device without filesystem": {
+			ndm:        newFakeNDMClient("bd-1", fakeBlockDevice("bd-1", "")),
+			volumeMode: v1.PersistentVolumeBlock,
+			expectPath: "/dev/disk/by-id/scsi-0QEMU_HARDDISK_1",
+		},
+		"no device available": {
+			ndm:        newFakeNDMClient(""),
+			volumeMode: v1.PersistentVolumeFilesystem,
+			expectErr:  true,
+		},
+		"device not found": {
+			ndm:        newFakeNDMClient("bd-2", fakeBlockDevice("bd-1", "/mnt/bd-1")),
+			volumeMode: v1.PersistentVolumeFilesystem,
+			expectErr:  true,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			p := &Provisioner{namespace: "openebs", ndm: v.ndm}
+
+			pv, state, err := p.ProvisionBlockDevice(context.TODO(), fakeDeviceProvisionOptions(v.volumeMode), fakeDeviceConfig())
+			if state != pvController.ProvisioningFinished {
+				t.Errorf("expected state %v got %v", pvController.ProvisioningFinished, state)
+			}
+			if v.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got pv %v", pv)
+				}
+				if _, ok := v.ndm.bdcs["bdc-pvName"]; ok && v.ndm.bindTo == "" {
+					t.Errorf("expected unbound BDC to be deleted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if pv.Spec.Local.Path != v.expectPath {
+				t.Errorf("expected path %v got %v", v.expectPath, pv.Spec.Local.Path)
+			}
+			if pv.Annotations[bdcStorageClassAnnotation] != "bdc-pvName" {
+				t.Errorf("expected BDC annotation bdc-pvName got %v", pv.Annotations[bdcStorageClassAnnotation])
+			}
+			if pv.Labels[string(mconfig.CASTypeKey)] != "local-device" {
+				t.Errorf("expected cas type local-device got %v", pv.Labels[string(mconfig.CASTypeKey)])
+			}
+			if v.volumeMode == v1.PersistentVolumeBlock &&
+				(pv.Spec.VolumeMode == nil || *pv.Spec.VolumeMode != v1.PersistentVolumeBlock) {
+				t.Errorf("expected volume mode %v got %v", v.volumeMode, pv.Spec.VolumeMode)
+			}
+
+			bdc := v.ndm.bdcs["bdc-pvName"]
+			if bdc == nil {
+				t.Fatalf("expected BDC bdc-pvName to be created")
+			}
+			if len(bdc.Finalizers) != 1 || bdc.Finalizers[0] != LocalPVFinalizer {
+				t.Errorf("expected finalizer %v got %v", LocalPVFinalizer, bdc.Finalizers)
+			}
+			if bdc.Spec.Selector.MatchLabels["ndm.io/fsType"] != "ext4" ||
+				bdc.Spec.Selector.MatchLabels[k8sNodeLabelKeyHostname] != "node-1" {
+				t.Errorf("unexpected BDC selector %v", bdc.Spec.Selector.MatchLabels)
+			}
+		})
+	}
+}
+
+func TestDeleteBlockDevice(t *testing.T) {
+	ndm := newFakeNDMClient("bd-1", fakeBlockDevice("bd-1", "/mnt/bd-1"))
+	p := &Provisioner{namespace: "openebs", ndm: ndm}
+
+	pv, _, err := p.ProvisionBlockDevice(context.TODO(), fakeDeviceProvisionOptions(v1.PersistentVolumeFilesystem), fakeDeviceConfig())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := p.DeleteBlockDevice(context.TODO(), pv); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := ndm.bdcs["bdc-pvName"]; ok {
+		t.Errorf("expected BDC bdc-pvName to be deleted")
+	}
+
+	// A PV without a BDC does not need any clean up.
+	pv.Annotations = nil
+	if err := p.DeleteBlockDevice(context.TODO(), pv); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"path/filepath"
 	"regexp"
@@ -30,9 +31,10 @@ import (
 
 	hostpath "github.com/openebs/maya/pkg/hostpath/v1alpha1"
 	errors "github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/container"
 	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/pod"
@@ -43,12 +45,48 @@ type podConfig struct {
 	pOpts                         *HelperPodOptions
 	parentDir, volumeDir, podName string
 	taints                        []corev1.Taint
+	//sourceDir is mounted read-only at /source, if set.
+	sourceDir string
+	//createParentDir creates the parentDir on the node, if it does
+	//not exist.
+	createParentDir bool
+	//seedSecret is the name of the Secret mounted read-only at /seed,
+	//if set.
+	seedSecret string
+	//seedTarball is the path of the tar archive on the node mounted
+	//read-only at /seed.tar, if set.
+	seedTarball string
+	//timeoutCounts is the activeDeadlineSeconds of the helper job.
+	//Defaults to CmdTimeoutCounts.
+	timeoutCounts int
+	//backoffLimit is the number of times the pod of the helper job is
+	//retried. Defaults to helperJobBackoffLimit.
+	backoffLimit *int32
+	//security is the privileges of the helper pod. Without privileges,
+	//the helper pod can only access the files it owns.
+	security helperPodSecurity
 }
 
 var (
 	//CmdTimeoutCounts specifies the duration to wait for cleanup pod
 	//to be launched.
 	CmdTimeoutCounts = 120
+
+	//CloneTimeoutCounts specifies the duration in seconds to wait for
+	//the clone pod to copy the data of the source volume.
+	CloneTimeoutCounts = 3600
+
+	//WipeTimeoutCounts specifies the duration in seconds to wait for
+	//the helper pod to overwrite the data of a volume.
+	WipeTimeoutCounts = 6 * 3600
+
+	//progressInterval is the interval at which the progress of a
+	//helper pod is read from its logs.
+	progressInterval = 10 * time.Second
+
+	//failedPodLogLines is the number of lines of the logs of a failed
+	//helper pod included in the error.
+	failedPodLogLines = int64(20)
 )
 
 // HelperPodOptions contains the options that
@@ -69,6 +107,11 @@ type HelperPodOptions struct {
 	//path is the volume hostpath directory
 	path string
 
+	//rootPath is the directory under which the volume hostpath directory
+	//was created. If set, the helper pod mounts the rootPath and verifies
+	//that none of the directories between rootPath and path is a symlink.
+	rootPath string
+
 	//serviceAccountName is the service account with which the pod should be launched
 	serviceAccountName string
 
@@ -84,6 +127,64 @@ type HelperPodOptions struct {
 
 	//pvcStorage is the storage requested for pv
 	pvcStorage int64
+
+	//quotaProjectID is the quota project ID allocated to the volume
+	//directory by the provisioner
+	quotaProjectID uint32
+
+	//sourcePath is the hostpath directory of the volume being cloned,
+	//or of the snapshot being restored or taken
+	sourcePath string
+
+	//sourceMode is either sourceModeCopy or sourceModeTar
+	sourceMode string
+
+	//dirMode, if set, is applied to the volume directory with chmod
+	dirMode string
+
+	//dirUID and dirGID, if set, are applied to the volume directory
+	//with chown
+	dirUID string
+	dirGID string
+
+	//dirDefaultACL, if set, is applied to the volume directory as the
+	//default ACL with setfacl
+	dirDefaultACL string
+
+	//selinuxContext, if set, is applied to the volume directory and its
+	//contents with chcon, if SELinux is enabled on the node
+	selinuxContext string
+
+	//seedSecret and seedTarball, if set, are the Secret and the tar
+	//archive on the node with which an empty volume directory is
+	//populated
+	seedSecret  string
+	seedTarball string
+
+	//wipePolicy, if set, is applied to the files of the volume
+	//directory, or to the block device, before it is released
+	wipePolicy string
+
+	//progress, if set, is called with the last line of the logs of
+	//the helper pod while it runs
+	progress func(string)
+
+	//pvName, pvcName and pvcNamespace, if set, identify the volume of
+	//the helper operation in the labels of the helper job
+	pvName       string
+	pvcName      string
+	pvcNamespace string
+
+	//eventObject is the PVC or PV on which the events of the helper
+	//pod are emitted
+	eventObject runtime.Object
+
+	//helperImage, if set, overrides the helper image of the provisioner
+	helperImage string
+
+	//podTemplate, if set, is the name of the ConfigMap of the helper
+	//pod template, which overrides the default helper pod template
+	podTemplate string
 }
 
 // validate checks that the required fields to launch
@@ -127,6 +228,105 @@ func (pOpts *HelperPodOptions) validateLimits() error {
 	return nil
 }
 
+// extractSubPath validates that the volume directory is not directly
+// under root and returns the directory to be mounted into the helper
+// pod along with the path of the volume directory relative to it.
+// The rootPath is mounted if it is set, else the parent directory of
+// the volume directory is mounted.
+func (pOpts *HelperPodOptions) extractSubPath() (string, string, error) {
+	parentDir, volumeDir, err := hostpath.NewBuilder().WithPath(pOpts.path).
+		WithCheckf(hostpath.IsNonRoot(), "volume directory {%v} should not be under root directory", pOpts.path).
+		ExtractSubPath()
+	if err != nil || pOpts.rootPath == "" {
+		return parentDir, volumeDir, err
+	}
+
+	if !isSubPath(pOpts.rootPath, pOpts.path) {
+		return "", "", errors.Errorf("volume directory {%v} should be under {%v}", pOpts.path, pOpts.rootPath)
+	}
+	relPath, err := filepath.Rel(pOpts.rootPath, pOpts.path)
+	if err != nil {
+		return "", "", err
+	}
+	return pOpts.rootPath, relPath, nil
+}
+
+// checkNoSymlinks returns the shell commands that fail the helper pod
+// if any of the directories of the volume path, relative to the mount
+// at /data, is a symlink. This prevents the helper pod commands from
+// escaping the mounted directory.
+func checkNoSymlinks(volumeDir string) string {
+	return "d=/data; for c in " + strings.Join(strings.Split(volumeDir, "/"), " ") + "; do " +
+		"d=$d/$c; if [ -L \"$d\" ]; then echo \"$d is a symlink\"; exit 1; fi; done; "
+}
+
+// seedCmd returns the command to populate the volume directory from
+// the seed Secret or tarball. The command is chained to the command
+// which creates the directory, and is skipped if the directory is not
+// empty.
+func seedCmd(pOpts *HelperPodOptions, volumePath string) string {
+	populateCmd := ""
+	switch {
+	case pOpts.seedSecret != "":
+		// The keys are symlinks into a hidden directory of the Secret
+		// volume.
+		populateCmd = "for f in /seed/*; do if [ -f \"$f\" ]; then cp -L \"$f\" " + volumePath + "/ || exit 1; fi; done"
+	case pOpts.seedTarball != "":
+		populateCmd = "tar -xf /seed.tar -C " + volumePath
+	default:
+		return ""
+	}
+	return " && if [ -z \"$(ls -A " + volumePath + ")\" ]; then " + populateCmd + "; fi"
+}
+
+// volumeDirPermissionsCmd returns the commands to apply the mode, owner,
+// default ACL and SELinux context to the volume directory. The commands
+// are chained to the command which creates the directory. The mode and
+// owner are applied to the contents of the directory if it is seeded.
+// The files get the mode without the execute and special bits.
+func volumeDirPermissionsCmd(pOpts *HelperPodOptions, volumePath string) string {
+	seeded := pOpts.seedSecret != "" || pOpts.seedTarball != ""
+	cmd := ""
+	if pOpts.dirMode != "" {
+		if seeded {
+			cmd += " && find " + volumePath + " -type d -exec chmod " + pOpts.dirMode + " {} +" +
+				" && find " + volumePath + " -type f -exec chmod " + fileMode(pOpts.dirMode) + " {} +"
+		} else {
+			cmd += " && chmod " + pOpts.dirMode + " " + volumePath
+		}
+	}
+	if pOpts.dirUID != "" || pOpts.dirGID != "" {
+		owner := pOpts.dirUID
+		if pOpts.dirGID != "" {
+			owner += ":" + pOpts.dirGID
+		}
+		chown := " && chown "
+		if seeded {
+			chown = " && chown -R "
+		}
+		cmd += chown + owner + " " + volumePath
+	}
+	if pOpts.dirDefaultACL != "" {
+		cmd += " && setfacl -d -m " + pOpts.dirDefaultACL + " " + volumePath
+	}
+	// selinuxfs is only mounted if SELinux is enabled on the node.
+	if pOpts.selinuxContext != "" {
+		cmd += " && if [ -e /sys/fs/selinux/enforce ]; then chcon -R " + pOpts.selinuxContext + " " + volumePath +
+			"; else echo \"SELinux is disabled, skipping relabel of " + volumePath + "\"; fi"
+	}
+	return cmd
+}
+
+// fileMode returns the octal mode of a file in a directory with the
+// given octal mode, without the execute and special bits.
+func fileMode(dirMode string) string {
+	mode, err := strconv.ParseUint(dirMode, 8, 32)
+	if err != nil {
+		return dirMode
+	}
+	return fmt.Sprintf("%04o", mode&0666)
+}
+
 // converToK converts the limits to kilobytes
 func convertToK(limit string, pvcStorage int64) (string, error) {
 
@@ -168,6 +368,10 @@ func convertToK(limit string, pvcStorage int64) (string, error) {
 func (p *Provisioner) createInitPod(ctx context.Context, pOpts *HelperPodOptions) error {
 	var config podConfig
 	config.pOpts, config.podName = pOpts, "init"
+	config.security = dacHelperPod
+	if pOpts.selinuxContext != "" {
+		config.security = relabelHelperPod
+	}
 	//err := pOpts.validate()
 	if err := pOpts.validate(); err != nil {
 		return err
@@ -177,9 +381,7 @@ func (p *Provisioner) createInitPod(ctx context.Context, pOpts *HelperPodOptions
 	// volume directory is not directly under root.
 	// Extract the base path and the volume unique path.
 	var vErr error
-	config.parentDir, config.volumeDir, vErr = hostpath.NewBuilder().WithPath(pOpts.path).
-		WithCheckf(hostpath.IsNonRoot(), "volume directory {%v} should not be under root directory", pOpts.path).
-		ExtractSubPath()
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
 	if vErr != nil {
 		return vErr
 	}
@@ -187,7 +389,14 @@ func (p *Provisioner) createInitPod(ctx context.Context, pOpts *HelperPodOptions
 	//Pass on the taints, to create tolerations.
 	config.taints = pOpts.selectedNodeTaints
 
-	config.pOpts.cmdsForPath = append(config.pOpts.cmdsForPath, filepath.Join("/data/", config.volumeDir))
+	config.seedSecret = pOpts.seedSecret
+	config.seedTarball = pOpts.seedTarball
+
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) +
+		strings.Join(append(config.pOpts.cmdsForPath, volumePath), " ") +
+		seedCmd(pOpts, volumePath) +
+		volumeDirPermissionsCmd(pOpts, volumePath)}
 
 	iPod, err := p.launchPod(ctx, config)
 	if err != nil {
@@ -210,6 +419,7 @@ func (p *Provisioner) createInitPod(ctx context.Context, pOpts *HelperPodOptions
 func (p *Provisioner) createCleanupPod(ctx context.Context, pOpts *HelperPodOptions) error {
 	var config podConfig
 	config.pOpts, config.podName = pOpts, "cleanup"
+	config.security = dacHelperPod
 	//err := pOpts.validate()
 	if err := pOpts.validate(); err != nil {
 		return err
@@ -219,87 +429,377 @@ func (p *Provisioner) createCleanupPod(ctx context.Context, pOpts *HelperPodOpti
 	// volume directory is not directly under root.
 	// Extract the base path and the volume unique path.
 	var vErr error
-	config.parentDir, config.volumeDir, vErr = hostpath.NewBuilder().WithPath(pOpts.path).
-		WithCheckf(hostpath.IsNonRoot(), "volume directory {%v} should not be under root directory", pOpts.path).
-		ExtractSubPath()
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
 	if vErr != nil {
 		return vErr
 	}
 
 	config.taints = pOpts.selectedNodeTaints
 
-	config.pOpts.cmdsForPath = append(config.pOpts.cmdsForPath, filepath.Join("/data/", config.volumeDir))
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) +
+		wipeFilesCmd(pOpts.wipePolicy, volumePath) +
+		strings.Join(append(config.pOpts.cmdsForPath, volumePath), " ")}
+
+	config.timeoutCounts = CmdTimeoutCounts
+	if pOpts.wipePolicy != "" {
+		config.timeoutCounts = WipeTimeoutCounts
+	}
 
 	cPod, err := p.launchPod(ctx, config)
 	if err != nil {
 		return err
 	}
 
-	if err := p.exitPod(ctx, cPod); err != nil {
+	if err := p.exitPodWithProgress(ctx, cPod, config.timeoutCounts, pOpts.progress); err != nil {
 		return err
 	}
 	return nil
 }
 
-// createQuotaPod launches a helper(busybox) pod, to apply the quota.
+// createWipeDevicePod launches a helper(busybox) pod, to wipe the block
 //
-//	The local pv expect the hostpath to be already present before mounting
-//	into pod. Validate that the local pv host path is not created under root.
-func (p *Provisioner) createQuotaPod(ctx context.Context, pOpts *HelperPodOptions) error {
+//	device of a device volume before its BlockDeviceClaim is released.
+//	The block device is wiped if the volume is in Block mode, else the
+//	files of the filesystem mounted at the path are overwritten.
+func (p *Provisioner) createWipeDevicePod(ctx context.Context, pOpts *HelperPodOptions, volumeMode corev1.PersistentVolumeMode) error {
 	var config podConfig
-	config.pOpts, config.podName = pOpts, "quota"
-	//err := pOpts.validate()
+	config.pOpts, config.podName = pOpts, "wipe"
+	if err := pOpts.validate(); err != nil {
+		return err
+	}
+
+	config.taints = pOpts.selectedNodeTaints
+
+	var wipeCmd string
+	config.security = dacHelperPod
+	if volumeMode == corev1.PersistentVolumeBlock {
+		// The block device is accessed via the /dev mount.
+		config.security = deviceHelperPod
+		config.parentDir = "/dev"
+		wipeCmd = wipeDeviceCmd(pOpts.wipePolicy, pOpts.path)
+	} else {
+		config.parentDir = pOpts.path
+		wipeCmd = wipeFilesCmd(pOpts.wipePolicy, "/data")
+	}
+	if wipeCmd == "" {
+		return errors.Errorf("invalid wipe policy {%v}", pOpts.wipePolicy)
+	}
+	config.pOpts.cmdsForPath = []string{"sh", "-c", wipeCmd}
+
+	config.timeoutCounts = WipeTimeoutCounts
+	wPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.exitPodWithProgress(ctx, wPod, WipeTimeoutCounts, pOpts.progress); err != nil {
+		return err
+	}
+	return nil
+}
+
+// createTrashPod launches a helper(busybox) pod, to move the host path
+//
+//	into the trash directory of the rootPath, as the given entry. The
+//	move is skipped if the host path no longer exists, so that it can
+//	be retried.
+func (p *Provisioner) createTrashPod(ctx context.Context, pOpts *HelperPodOptions, entry string) error {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, "trash"
+	config.security = dacHelperPod
 	if err := pOpts.validate(); err != nil {
 		return err
 	}
 
-	// Initialize HostPath builder and validate that
-	// volume directory is not directly under root.
-	// Extract the base path and the volume unique path.
 	var vErr error
-	config.parentDir, config.volumeDir, vErr = hostpath.NewBuilder().WithPath(pOpts.path).
-		WithCheckf(hostpath.IsNonRoot(), "volume directory {%v} should not be under root directory", pOpts.path).
-		ExtractSubPath()
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
 	if vErr != nil {
 		return vErr
 	}
 
-	//Pass on the taints, to create tolerations.
 	config.taints = pOpts.selectedNodeTaints
 
-	var lErr error
-	config.pOpts.softLimitGrace, lErr = convertToK(config.pOpts.softLimitGrace, config.pOpts.pvcStorage)
-	if lErr != nil {
-		return lErr
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	trashPath := filepath.Join("/data/", trashDir, entry)
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) +
+		checkNoSymlinks(trashDir) +
+		"mkdir -p /data/" + trashDir + " && if [ -e " + volumePath + " ]; then mv " + volumePath + " " + trashPath + "; fi"}
+
+	tPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
 	}
-	config.pOpts.hardLimitGrace, lErr = convertToK(config.pOpts.hardLimitGrace, config.pOpts.pvcStorage)
-	if lErr != nil {
-		return lErr
+
+	if err := p.exitPod(ctx, tPod); err != nil {
+		return err
 	}
+	return nil
+}
 
-	if err := pOpts.validateLimits(); err != nil {
+// createRestorePod launches a helper(busybox) pod, to move the given
+//
+//	entry of the trash directory of the rootPath back to the host path.
+//	The pod fails if the host path already exists, unless the entry was
+//	already moved back.
+func (p *Provisioner) createRestorePod(ctx context.Context, pOpts *HelperPodOptions, entry string) error {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, "restore"
+	config.security = dacHelperPod
+	if err := pOpts.validate(); err != nil {
+		return err
+	}
+
+	var vErr error
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
+	if vErr != nil {
+		return vErr
+	}
+
+	config.taints = pOpts.selectedNodeTaints
+
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	trashPath := filepath.Join("/data/", trashDir, entry)
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) +
+		checkNoSymlinks(trashDir) +
+		"if [ -e " + trashPath + " ]; then " +
+		"if [ -e " + volumePath + " ]; then echo \"" + volumePath + " already exists\"; exit 1; fi; " +
+		"mkdir -p " + filepath.Dir(volumePath) + " && mv " + trashPath + " " + volumePath + "; " +
+		"elif [ ! -e " + volumePath + " ]; then echo \"" + trashPath + " not found\"; exit 1; fi"}
+
+	rPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.exitPod(ctx, rPod); err != nil {
+		return err
+	}
+	return nil
+}
+
+// createClonePod launches a helper(busybox) pod, to copy the data of the
+//
+//	clone source volume or snapshot into the volume directory. The source
+//	directory is mounted read-only. Reflinks are used if supported by cp
+//	and by the filesystem, else the data is copied. Snapshots taken as a
+//	tarball are extracted.
+func (p *Provisioner) createClonePod(ctx context.Context, pOpts *HelperPodOptions) error {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, "clone"
+	config.security = dacHelperPod
+	if err := pOpts.validate(); err != nil {
 		return err
 	}
+	if pOpts.sourcePath == "" {
+		return errors.Errorf("invalid empty clone source path")
+	}
 
+	var vErr error
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
+	if vErr != nil {
+		return vErr
+	}
+	config.sourceDir = pOpts.sourcePath
+
+	config.taints = pOpts.selectedNodeTaints
+
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	copyCmd, err := copyFromSourceCmd(pOpts.sourceMode, volumePath)
+	if err != nil {
+		return err
+	}
+	// The copy also copies the mode and owner of the source directory,
+	// so that the ones of the volume are applied again.
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) + copyCmd +
+		volumeDirPermissionsCmd(pOpts, volumePath)}
+
+	config.timeoutCounts = CloneTimeoutCounts
+	cPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.exitPodWithTimeout(ctx, cPod, CloneTimeoutCounts); err != nil {
+		return err
+	}
+	return nil
+}
+
+// createSnapshotPod launches a helper(busybox) pod, to copy the data of
+//
+//	the volume directory into the snapshot directory. The volume directory
+//	is mounted read-only, and the snapshot root directory is created if
+//	it does not exist.
+func (p *Provisioner) createSnapshotPod(ctx context.Context, pOpts *HelperPodOptions) error {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, "snapshot"
+	config.security = dacHelperPod
+	if err := pOpts.validate(); err != nil {
+		return err
+	}
+	if pOpts.sourcePath == "" {
+		return errors.Errorf("invalid empty snapshot source path")
+	}
+
+	var vErr error
+	config.parentDir, config.volumeDir, vErr = pOpts.extractSubPath()
+	if vErr != nil {
+		return vErr
+	}
+	config.sourceDir = pOpts.sourcePath
+	config.createParentDir = true
+
+	config.taints = pOpts.selectedNodeTaints
+
+	snapshotPath := filepath.Join("/data/", config.volumeDir)
+	var copyCmd string
+	switch pOpts.sourceMode {
+	case sourceModeCopy:
+		copyCmd = reflinkCopyCmd(snapshotPath)
+	case sourceModeTar:
+		copyCmd = "tar -cf " + filepath.Join(snapshotPath, "snapshot.tar") + " -C /source ."
+	default:
+		return errors.Errorf("invalid snapshot mode %q", pOpts.sourceMode)
+	}
+	config.pOpts.cmdsForPath = []string{"sh", "-c", checkNoSymlinks(config.volumeDir) +
+		"mkdir -p " + snapshotPath + " && " + copyCmd}
+
+	config.timeoutCounts = CloneTimeoutCounts
+	sPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.exitPodWithTimeout(ctx, sPod, CloneTimeoutCounts); err != nil {
+		return err
+	}
+	return nil
+}
+
+// copyFromSourceCmd returns the shell command that populates the
+// directory at path from the data source mounted at /source.
+func copyFromSourceCmd(mode, path string) (string, error) {
+	switch mode {
+	case sourceModeCopy:
+		return reflinkCopyCmd(path), nil
+	case sourceModeTar:
+		return "tar -xf /source/snapshot.tar -C " + path, nil
+	default:
+		return "", errors.Errorf("invalid data source mode %q", mode)
+	}
+}
+
+// reflinkCopyCmd returns the shell command that copies the contents of
+// /source into the directory at path, using reflinks if cp supports them.
+func reflinkCopyCmd(path string) string {
+	return "" +
+		"if cp --help 2>&1 | grep -q -- --reflink; then " +
+		"  cp -a --reflink=auto /source/. " + path + "/ ; " +
+		"else " +
+		"  cp -a /source/. " + path + "/ ; fi"
+}
+
+// createCapacityPod launches a helper(busybox) pod, to measure the space
+//
+//	used and available in the filesystem of the directory (path) in
+//	bytes. The directory is created if it does not exist.
+func (p *Provisioner) createCapacityPod(ctx context.Context, pOpts *HelperPodOptions) (int64, int64, error) {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, "capacity"
+	if err := pOpts.validate(); err != nil {
+		return 0, 0, err
+	}
+
+	_, err := hostpath.NewBuilder().WithPath(pOpts.path).
+		WithCheckf(hostpath.IsNonRoot(), "directory {%v} should not be the root directory", pOpts.path).
+		ValidateAndBuild()
+	if err != nil {
+		return 0, 0, err
+	}
+	config.parentDir = pOpts.path
+	config.createParentDir = true
+
+	config.taints = pOpts.selectedNodeTaints
+
+	//df reports the used and available space in 1K blocks
+	config.pOpts.cmdsForPath = []string{"sh", "-c", "df -P -k /data | awk 'NR==2{print $3, $4}'"}
+
+	cPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	out, err := p.exitPodWithOutput(ctx, cPod, CmdTimeoutCounts)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, errors.Errorf("invalid output of capacity pod %v: %q", cPod.Name, out)
+	}
+	usedK, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid output of capacity pod %v: %q", cPod.Name, out)
+	}
+	availableK, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid output of capacity pod %v: %q", cPod.Name, out)
+	}
+	return usedK * 1024, availableK * 1024, nil
+}
+
+// createQuotaPod launches a helper(busybox) pod, to apply the quota.
+//
+//	The local pv expect the hostpath to be already present before mounting
+//	into pod. Validate that the local pv host path is not created under root.
+//	The volume directory is set with the project ID allocated by the
+//	provisioner, after verifying that no other directory uses it.
+func (p *Provisioner) createQuotaPod(ctx context.Context, pOpts *HelperPodOptions) error {
+	config, err := newQuotaPodConfig(pOpts, "quota")
+	if err != nil {
+		return err
+	}
+	if config.pOpts.quotaProjectID == 0 {
+		return errors.Errorf("quota project ID is not allocated for volume %v", config.pOpts.name)
+	}
+
+	volumePath := filepath.Join("/data/", config.volumeDir)
 	//fs stores the file system of mount
-	fs := "FS=`stat -f -c %T /data` ; "
+	fs := "FS=`stat -f -c %T /data` ; PID=" + strconv.FormatUint(uint64(config.pOpts.quotaProjectID), 10) + " ; "
+	//inUse exits with quotaProjectInUseExitCode and logs the project
+	//IDs in use if PID is used by files other than the volume directory,
+	//which already has PID set if the quota is applied again.
+	inUse := "" +
+		"  if [ \"$CUR\" != \"$PID\" ]; then case \" $USED \" in *\" $PID \"*)" +
+		"    echo \"" + quotaProjectInUseMessage + " $USED\" ; exit " + strconv.Itoa(quotaProjectInUseExitCode) + " ;; esac ; fi ;"
+	//isSet fails if the project ID of the volume directory is not PID
+	isSet := "  if [ \"$CUR\" != \"$PID\" ]; then echo \"quota project $PID is not set on the volume directory\" ; exit 1; fi ;"
 	//check if fs is xfs or ext4 (output of stat is ext2/ext3)
-	//PID is the last project Id in the directory
-	//xfs_quota project(xfs) or chattr +P (ext4) initializes project with new project id
-	//xfs_quota limit(xfs) or repquota (ext4) sets the quota according to limits defined
+	//CUR is the project ID of the volume directory, read using xfs_io
+	//lsproj (xfs) or lsattr -p (ext4), and USED are the project IDs
+	//with files, read using xfs_quota report (xfs) or repquota (ext4)
+	//xfs_quota project(xfs) or chattr +P (ext4) sets the project id on the volume directory
+	//xfs_quota limit(xfs) or setquota (ext4) sets the quota according to limits defined
+	xfsProject := "CUR=`xfs_io -r -c lsproj " + volumePath + " | awk -F'= ' '{print $2}'` ;"
+	ext4Project := "CUR=`lsattr -pd " + volumePath + " | awk '{print $1}'` ;"
 	checkQuota := "" +
 		"if [[ \"$FS\" == \"xfs\" ]]; then " +
-		"  PID=`xfs_quota -x -c 'report -h' /data | tail -2 | awk 'NR==1{print substr ($1,2)}+0'` ;" +
-		"  PID=`expr $PID + 1` ;" +
-		"  xfs_quota -x -c 'project -s -p " + filepath.Join("/data/", config.volumeDir) + " '$PID /data;" +
+		"  " + xfsProject +
+		"  USED=`xfs_quota -x -c 'report -p -i -n -N' /data | awk '$2>0 && substr($1,2)+0>0 {print substr($1,2)}' | tr '\\n' ' '` ;" +
+		inUse +
+		"  xfs_quota -x -c 'project -s -p " + volumePath + " '$PID /data;" +
+		"  " + xfsProject + isSet +
 		"  xfs_quota -x -c 'limit -p bsoft=" + config.pOpts.softLimitGrace + " bhard=" + config.pOpts.hardLimitGrace + " '$PID /data ;" +
 		"elif [[ \"$FS\" == \"ext2/ext3\" ]]; then" +
-		"  PID=`repquota -P /data | tail -3 | awk 'NR==1{print substr ($1,2)}+0'` ;" +
-		"  PID=`expr $PID + 1` ;" +
-		"  chattr +P -p $PID " + filepath.Join("/data/", config.volumeDir) + " ;" +
+		"  " + ext4Project +
+		"  USED=`repquota -P -n /data | awk '/^#/ && $3>0 && substr($1,2)+0>0 {print substr($1,2)}' | tr '\\n' ' '` ;" +
+		inUse +
+		"  chattr +P -p $PID " + volumePath + " ;" +
+		"  " + ext4Project + isSet +
 		"  setquota -P $PID " + strings.ToUpper(config.pOpts.softLimitGrace) + " " + strings.ToUpper(config.pOpts.hardLimitGrace) + " 0 0 " + "/data ; " +
 		"else " +
-		"  rm -rf " + filepath.Join("/data/", config.volumeDir) + " ; exit 1; fi"
+		"  rm -rf " + volumePath + " ; exit 1; fi"
 	config.pOpts.cmdsForPath = []string{"sh", "-c", fs + checkQuota}
 
 	qPod, err := p.launchPod(ctx, config)
@@ -314,84 +814,279 @@ func (p *Provisioner) createQuotaPod(ctx context.Context, pOpts *HelperPodOption
 	return nil
 }
 
-func (p *Provisioner) launchPod(ctx context.Context, config podConfig) (*corev1.Pod, error) {
-	// the helper pod need to be launched in privileged mode. This is because in CoreOS
-	// nodes, pods without privileged access cannot write to the host directory.
-	// Helper pods need to create and delete directories on the host.
-	privileged := true
+// createQuotaResizePod launches a helper(busybox) pod, to update the
+//
+//	limits of the quota project already set on the volume directory.
+//	Unlike createQuotaPod, a new project is never initialized and the
+//	volume directory is left untouched if the project cannot be found,
+//	or differs from the project ID allocated to the volume.
+func (p *Provisioner) createQuotaResizePod(ctx context.Context, pOpts *HelperPodOptions) error {
+	config, err := newQuotaPodConfig(pOpts, "quota-resize")
+	if err != nil {
+		return err
+	}
+
+	volumePath := filepath.Join("/data/", config.volumeDir)
+	//fs stores the file system of mount
+	fs := "FS=`stat -f -c %T /data` ; "
+	//PID is the project Id already set on the volume directory, read
+	//using xfs_io lsproj (xfs) or lsattr -p (ext4). Project Id 0 means
+	//that no quota was applied to the directory at provisioning time.
+	//If the provisioner allocated the project Id, PID must match it.
+	checkProject := "  if [ -z \"$PID\" ] || [ \"$PID\" -eq 0 ]; then exit 1; fi ;"
+	if id := config.pOpts.quotaProjectID; id != 0 {
+		checkProject += "  if [ \"$PID\" -ne " + strconv.FormatUint(uint64(id), 10) + " ]; then" +
+			" echo \"quota project $PID of the volume directory is not " + strconv.FormatUint(uint64(id), 10) + "\" ; exit 1; fi ;"
+	}
+	//xfs_quota limit(xfs) or setquota (ext4) updates the limits of the project
+	updateQuota := "" +
+		"if [[ \"$FS\" == \"xfs\" ]]; then " +
+		"  PID=`xfs_io -r -c lsproj " + volumePath + " | awk -F'= ' '{print $2}'` ;" +
+		checkProject +
+		"  xfs_quota -x -c 'limit -p bsoft=" + config.pOpts.softLimitGrace + " bhard=" + config.pOpts.hardLimitGrace + " '$PID /data ;" +
+		"elif [[ \"$FS\" == \"ext2/ext3\" ]]; then" +
+		"  PID=`lsattr -pd " + volumePath + " | awk '{print $1}'` ;" +
+		checkProject +
+		"  setquota -P $PID " + strings.ToUpper(config.pOpts.softLimitGrace) + " " + strings.ToUpper(config.pOpts.hardLimitGrace) + " 0 0 " + "/data ; " +
+		"else " +
+		"  exit 1; fi"
+	config.pOpts.cmdsForPath = []string{"sh", "-c", fs + updateQuota}
+
+	qPod, err := p.launchPod(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if err := p.exitPod(ctx, qPod); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// newQuotaPodConfig validates the helper pod options and builds the
+// pod config used by the quota helper pods. The soft and hard limit
+// grace are converted to kilobytes based on the requested pvc storage.
+func newQuotaPodConfig(pOpts *HelperPodOptions, podName string) (podConfig, error) {
+	var config podConfig
+	config.pOpts, config.podName = pOpts, podName
+	config.security = quotaHelperPod
+	// The quota pods are not retried by the job, as their failure, e.g.
+	// a project ID in use, is handled by the caller.
+	noRetry := int32(0)
+	config.backoffLimit = &noRetry
+	if err := pOpts.validate(); err != nil {
+		return config, err
+	}
+
+	// Initialize HostPath builder and validate that
+	// volume directory is not directly under root.
+	// Extract the base path and the volume unique path.
+	var vErr error
+	config.parentDir, config.volumeDir, vErr = hostpath.NewBuilder().WithPath(pOpts.path).
+		WithCheckf(hostpath.IsNonRoot(), "volume directory {%v} should not be under root directory", pOpts.path).
+		ExtractSubPath()
+	if vErr != nil {
+		return config, vErr
+	}
+
+	//Pass on the taints, to create tolerations.
+	config.taints = pOpts.selectedNodeTaints
+
+	var lErr error
+	config.pOpts.softLimitGrace, lErr = convertToK(config.pOpts.softLimitGrace, config.pOpts.pvcStorage)
+	if lErr != nil {
+		return config, lErr
+	}
+	config.pOpts.hardLimitGrace, lErr = convertToK(config.pOpts.hardLimitGrace, config.pOpts.pvcStorage)
+	if lErr != nil {
+		return config, lErr
+	}
+
+	if err := pOpts.validateLimits(); err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+func (p *Provisioner) launchPod(ctx context.Context, config podConfig) (*batchv1.Job, error) {
+	// the helper pod is launched with only the privileges needed by its
+	// operation. In CoreOS nodes, pods without privileged access cannot
+	// write to the host directory, so all the helper pods can be launched
+	// in privileged mode with OPENEBS_IO_HELPER_POD_PRIVILEGED.
+	security := config.security
+	if isHelperPodPrivileged() {
+		security = privilegedHelperPod
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "data",
+			ReadOnly:  false,
+			MountPath: "/data/",
+		},
+	}
+	if security.mountDev {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "dev",
+			ReadOnly:  false,
+			MountPath: "/dev/",
+		})
+	}
+	if config.sourceDir != "" {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "source",
+			ReadOnly:  true,
+			MountPath: "/source/",
+		})
+	}
+	if config.seedSecret != "" {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      config.seedSecret,
+			ReadOnly:  true,
+			MountPath: "/seed/",
+		})
+	}
+	if config.seedTarball != "" {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "seed",
+			ReadOnly:  true,
+			MountPath: "/seed.tar",
+		})
+	}
+
+	dataVolume := volume.NewBuilder().
+		WithName("data").
+		WithHostDirectory(config.parentDir)
+	if config.createParentDir {
+		hostPathDirectoryOrCreate := corev1.HostPathDirectoryOrCreate
+		dataVolume = volume.NewBuilder().
+			WithName("data").
+			WithHostPathAndType(config.parentDir, &hostPathDirectoryOrCreate)
+	}
+
+	helperImage := p.helperImage
+	if config.pOpts.helperImage != "" {
+		helperImage = config.pOpts.helperImage
+	}
+
+	// The service account of the provisioner only exists in its own
+	// namespace. The helper pods do not access the API server.
+	serviceAccountName := config.pOpts.serviceAccountName
+	if p.getHelperNamespace() != p.namespace {
+		serviceAccountName = "default"
+	}
 
-	helperPod, err := pod.NewBuilder().
+	podBuilder := pod.NewBuilder().
 		WithName(config.podName + "-" + config.pOpts.name).
+		WithLabels(helperLabels(config)).
 		WithRestartPolicy(corev1.RestartPolicyNever).
 		//WithNodeSelectorHostnameNew(config.pOpts.nodeHostname).
 		WithNodeAffinityNew(config.pOpts.nodeAffinityLabels).
-		WithServiceAccountName(config.pOpts.serviceAccountName).
+		WithServiceAccountName(serviceAccountName).
 		WithTolerationsForTaints(config.taints...).
 		WithContainerBuilder(
 			container.NewBuilder().
 				WithName("local-path-" + config.podName).
-				WithImage(p.helperImage).
+				WithImage(helperImage).
 				WithCommandNew(config.pOpts.cmdsForPath).
-				WithVolumeMountsNew([]corev1.VolumeMount{
-					{
-						Name:      "data",
-						ReadOnly:  false,
-						MountPath: "/data/",
-					},
-					{
-						Name:      "dev",
-						ReadOnly:  false,
-						MountPath: "/dev/",
-					},
-				}).
-				WithPrivilegedSecurityContext(&privileged),
+				WithVolumeMountsNew(volumeMounts).
+				WithSecurityContext(security.securityContext()),
 		).
 		WithImagePullSecrets(config.pOpts.imagePullSecrets).
-		WithVolumeBuilder(
-			volume.NewBuilder().
-				WithName("data").
-				WithHostDirectory(config.parentDir),
-		).
-		WithVolumeBuilder(
+		WithVolumeBuilder(dataVolume)
+	if security.mountDev {
+		podBuilder = podBuilder.WithVolumeBuilder(
 			volume.NewBuilder().
 				WithName("dev").
 				WithHostDirectory("/dev/"),
-		).
-		Build()
+		)
+	}
+	if config.sourceDir != "" {
+		hostPathDirectory := corev1.HostPathDirectory
+		podBuilder = podBuilder.WithVolumeBuilder(
+			volume.NewBuilder().
+				WithName("source").
+				WithHostPathAndType(config.sourceDir, &hostPathDirectory),
+		)
+	}
+	if config.seedSecret != "" {
+		podBuilder = podBuilder.WithVolumeBuilder(
+			volume.NewBuilder().
+				WithSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: config.seedSecret}}, 0644),
+		)
+	}
+	if config.seedTarball != "" {
+		hostPathFile := corev1.HostPathFile
+		podBuilder = podBuilder.WithVolumeBuilder(
+			volume.NewBuilder().
+				WithName("seed").
+				WithHostPathAndType(config.seedTarball, &hostPathFile),
+		)
+	}
 
+	helperPod, err := podBuilder.Build()
 	if err != nil {
 		return nil, err
 	}
+	automountServiceAccountToken := false
+	helperPod.Spec.AutomountServiceAccountToken = &automountServiceAccountToken
 
-	var hPod *corev1.Pod
+	template, err := p.getHelperPodTemplate(ctx, config.pOpts.podTemplate)
+	if err != nil {
+		return nil, err
+	}
+	template.apply(helperPod)
+
+	//Launch the helper job, or adopt the helper job left behind
+	// by a restart of the provisioner.
+	hJob, err := p.createHelperJob(ctx, newHelperJob(config, helperPod))
+	if err != nil {
+		return nil, err
+	}
+	p.recordEvent(config.pOpts.eventObject, corev1.EventTypeNormal, eventCodeHelperLaunch,
+		"Launched %v helper job %v/%v", config.podName, hJob.Namespace, hJob.Name)
+	return hJob, nil
+}
 
-	//Launch the helper pod.
-	hPod, err = p.kubeClient.CoreV1().Pods(p.namespace).Create(ctx, helperPod, metav1.CreateOptions{})
-	return hPod, err
+func (p *Provisioner) exitPod(ctx context.Context, hJob *batchv1.Job) error {
+	return p.exitPodWithTimeout(ctx, hJob, CmdTimeoutCounts)
 }
 
-func (p *Provisioner) exitPod(ctx context.Context, hPod *corev1.Pod) error {
-	defer func() {
-		e := p.kubeClient.CoreV1().Pods(p.namespace).Delete(ctx, hPod.Name, metav1.DeleteOptions{})
-		if e != nil {
-			klog.Errorf("unable to delete the helper pod: %v", e)
-		}
-	}()
-
-	//Wait for the helper pod to complete it job and exit
-	completed := false
-	for i := 0; i < CmdTimeoutCounts; i++ {
-		checkPod, err := p.kubeClient.CoreV1().Pods(p.namespace).Get(ctx, hPod.Name, metav1.GetOptions{})
-		if err != nil {
-			return err
-		} else if checkPod.Status.Phase == corev1.PodSucceeded {
-			completed = true
-			break
-		}
-		time.Sleep(1 * time.Second)
+// exitPodWithTimeout waits for up to timeoutCounts seconds for the helper
+// job to complete and then deletes it. A failed helper job is kept for
+// inspection till its ttlSecondsAfterFinished.
+func (p *Provisioner) exitPodWithTimeout(ctx context.Context, hJob *batchv1.Job, timeoutCounts int) error {
+	err := p.waitForJob(ctx, hJob, timeoutCounts, nil)
+	p.deleteHelperJob(ctx, hJob, err)
+	return err
+}
+
+// exitPodWithProgress waits for up to timeoutCounts seconds for the
+// helper job to complete and then deletes it. While the job runs, the
+// progress function is called with the last line of the logs of its pod.
+func (p *Provisioner) exitPodWithProgress(ctx context.Context, hJob *batchv1.Job, timeoutCounts int, progress func(string)) error {
+	err := p.waitForJob(ctx, hJob, timeoutCounts, progress)
+	p.deleteHelperJob(ctx, hJob, err)
+	return err
+}
+
+// exitPodWithOutput waits for up to timeoutCounts seconds for the helper
+// job to complete, and returns the logs of its pod before deleting it.
+func (p *Provisioner) exitPodWithOutput(ctx context.Context, hJob *batchv1.Job, timeoutCounts int) (string, error) {
+	err := p.waitForJob(ctx, hJob, timeoutCounts, nil)
+	defer p.deleteHelperJob(ctx, hJob, err)
+	if err != nil {
+		return "", err
 	}
-	if !completed {
-		return errors.Errorf("create process timeout after %v seconds", CmdTimeoutCounts)
+	hPod, err := p.getJobPod(ctx, hJob)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	logs, err := p.kubeClient.CoreV1().Pods(p.getHelperNamespace()).GetLogs(hPod.Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get logs of helper pod %v", hPod.Name)
+	}
+	return string(logs), nil
 }
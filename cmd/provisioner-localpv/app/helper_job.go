@@ -0,0 +1,614 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/openebs/maya/pkg/alertlog"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// helperPodLabel is set on all the helper jobs launched by the
+	// provisioner, and on their pods.
+	helperPodLabel = "local.openebs.io/helper-pod"
+	// helperOperationLabel is set on the helper jobs with the operation
+	// of the job, e.g. init, cleanup or quota.
+	helperOperationLabel = "local.openebs.io/helper-operation"
+	// helperPVLabel, helperPVCLabel and helperPVCNamespaceLabel are set
+	// on the helper jobs with the PV and the PVC of the operation.
+	helperPVLabel           = "local.openebs.io/persistent-volume"
+	helperPVCLabel          = "local.openebs.io/persistent-volume-claim"
+	helperPVCNamespaceLabel = "local.openebs.io/persistent-volume-claim-namespace"
+
+	// helperJobBackoffLimit is the number of times the pod of a helper
+	// job is retried before the job fails.
+	helperJobBackoffLimit = int32(2)
+
+	// helperPodGCGracePeriod is the time for which a completed helper
+	// job is kept before it is garbage collected, so that an operation
+	// still waiting on it can take its result.
+	helperPodGCGracePeriod = 5 * time.Minute
+)
+
+// helperLabels returns the labels of the helper job and its pod. The
+// PV and PVC names are only set if they are valid label values.
+func helperLabels(config podConfig) map[string]string {
+	helperLabels := map[string]string{
+		helperPodLabel:       "true",
+		helperOperationLabel: config.podName,
+	}
+	for key, value := range map[string]string{
+		helperPVLabel:           config.pOpts.pvName,
+		helperPVCLabel:          config.pOpts.pvcName,
+		helperPVCNamespaceLabel: config.pOpts.pvcNamespace,
+	} {
+		if value != "" && len(validation.IsValidLabelValue(value)) == 0 {
+			helperLabels[key] = value
+		}
+	}
+	return helperLabels
+}
+
+// newHelperJob returns the batch/v1 Job that runs the helper pod. The
+// job is retried up to backoffLimit times, is stopped after
+// timeoutCounts seconds, and is removed after the helper job TTL once
+// it has finished.
+func newHelperJob(config podConfig, helperPod *corev1.Pod) *batchv1.Job {
+	backoffLimit := helperJobBackoffLimit
+	if config.backoffLimit != nil {
+		backoffLimit = *config.backoffLimit
+	}
+	activeDeadlineSeconds := int64(config.timeoutCounts)
+	if activeDeadlineSeconds == 0 {
+		activeDeadlineSeconds = int64(CmdTimeoutCounts)
+	}
+	ttlSecondsAfterFinished := int32(getHelperJobTTL().Seconds())
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   helperPod.Name,
+			Labels: helperPod.Labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      helperPod.Labels,
+					Annotations: helperPod.Annotations,
+				},
+				Spec: helperPod.Spec,
+			},
+		},
+	}
+}
+
+// createHelperJob creates the helper job. If a helper job with the same
+// name exists, e.g. left behind by a restart of the provisioner, it is
+// adopted if it runs the same command and has not failed. Otherwise the
+// existing job is deleted and the helper job is created again.
+func (p *Provisioner) createHelperJob(ctx context.Context, helperJob *batchv1.Job) (*batchv1.Job, error) {
+	jobClient := p.kubeClient.BatchV1().Jobs(p.getHelperNamespace())
+	hJob, err := jobClient.Create(ctx, helperJob, metav1.CreateOptions{})
+	if !k8serrors.IsAlreadyExists(err) {
+		return hJob, err
+	}
+
+	existing, err := jobClient.Get(ctx, helperJob.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get existing helper job %v", helperJob.Name)
+	}
+	if canAdoptHelperJob(existing, helperJob) {
+		klog.Infof("Adopting existing helper job %v", existing.Name)
+		return existing, nil
+	}
+
+	klog.Infof("Replacing existing helper job %v", existing.Name)
+	if err := p.deleteHelperJobAndWait(ctx, existing); err != nil {
+		return nil, err
+	}
+	return jobClient.Create(ctx, helperJob, metav1.CreateOptions{})
+}
+
+// canAdoptHelperJob returns true if the existing helper job runs the same
+// command as the new helper job, and has not failed.
+func canAdoptHelperJob(existing, helperJob *batchv1.Job) bool {
+	if existing.DeletionTimestamp != nil {
+		return false
+	}
+	if condition := getJobCondition(existing, batchv1.JobFailed); condition != nil {
+		return false
+	}
+	existingContainers := existing.Spec.Template.Spec.Containers
+	helperContainers := helperJob.Spec.Template.Spec.Containers
+	if len(existingContainers) != len(helperContainers) {
+		return false
+	}
+	for i := range existingContainers {
+		if !reflect.DeepEqual(existingContainers[i].Command, helperContainers[i].Command) {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteHelperJobAndWait deletes the helper job and waits till it is
+// gone, so that it can be created again with the same name.
+func (p *Provisioner) deleteHelperJobAndWait(ctx context.Context, hJob *batchv1.Job) error {
+	jobClient := p.kubeClient.BatchV1().Jobs(p.getHelperNamespace())
+	propagationPolicy := metav1.DeletePropagationBackground
+	err := jobClient.Delete(ctx, hJob.Name, metav1.DeleteOptions{
+		Preconditions:     &metav1.Preconditions{UID: &hJob.UID},
+		PropagationPolicy: &propagationPolicy,
+	})
+	if err != nil && !k8serrors.IsNotFound(err) && !k8serrors.IsConflict(err) {
+		return errors.Wrapf(err, "failed to delete existing helper job %v", hJob.Name)
+	}
+	err = wait.PollImmediateWithContext(ctx, time.Second, time.Duration(CmdTimeoutCounts)*time.Second,
+		func(ctx context.Context) (bool, error) {
+			current, err := jobClient.Get(ctx, hJob.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			return current.UID != hJob.UID, nil
+		})
+	return errors.Wrapf(err, "failed to wait for existing helper job %v to be deleted", hJob.Name)
+}
+
+// deleteHelperJob deletes the helper job and its pod once the job is
+// done. A job that failed is kept for inspection, and is removed after
+// its ttlSecondsAfterFinished.
+func (p *Provisioner) deleteHelperJob(ctx context.Context, hJob *batchv1.Job, err error) {
+	if jobErr, ok := errors.Cause(err).(*helperPodError); ok && jobErr.jobFailed {
+		return
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	e := p.kubeClient.BatchV1().Jobs(p.getHelperNamespace()).Delete(ctx, hJob.Name, metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+	if e != nil && !k8serrors.IsNotFound(e) {
+		klog.Errorf("unable to delete the helper job: %v", e)
+	}
+}
+
+// waitForJob watches the helper job for up to timeoutCounts seconds,
+// till it completes. It returns early with a helperPodError if the job
+// fails, or if its pod cannot pull its image or cannot be scheduled. If
+// progress is set, it is called every progressInterval with the last
+// line of the logs of the pod, if the line changed.
+func (p *Provisioner) waitForJob(ctx context.Context, hJob *batchv1.Job, timeoutCounts int, progress func(string)) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutCounts)*time.Second)
+	defer cancel()
+	defer observeHelperJob(hJob)()
+
+	if progress != nil {
+		go p.reportJobProgress(ctx, hJob, progress)
+	}
+
+	jobErr := make(chan error, 1)
+	go func() { jobErr <- p.watchJob(ctx, hJob) }()
+	podErr := make(chan error, 1)
+	go func() { podErr <- p.watchJobPods(ctx, hJob) }()
+
+	var err error
+	select {
+	case err = <-jobErr:
+	case err = <-podErr:
+	}
+	if err == wait.ErrWaitTimeout || errors.Is(err, context.DeadlineExceeded) {
+		return errors.Errorf("create process timeout after %v seconds", timeoutCounts)
+	}
+	return err
+}
+
+// watchJob returns when the helper job completes, or with an error when
+// the job fails or is deleted.
+func (p *Provisioner) watchJob(ctx context.Context, hJob *batchv1.Job) error {
+	jobClient := p.kubeClient.BatchV1().Jobs(p.getHelperNamespace())
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", hJob.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return jobClient.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return jobClient.Watch(ctx, options)
+		},
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &batchv1.Job{}, nil, func(event watch.Event) (bool, error) {
+		checkJob, ok := event.Object.(*batchv1.Job)
+		if !ok || checkJob.Name != hJob.Name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, errors.Errorf("helper job %v was deleted", hJob.Name)
+		}
+		if condition := getJobCondition(checkJob, batchv1.JobComplete); condition != nil {
+			return true, nil
+		}
+		if condition := getJobCondition(checkJob, batchv1.JobFailed); condition != nil {
+			jobErr := &helperPodError{
+				podName:   hJob.Name,
+				reason:    condition.Reason,
+				message:   condition.Message,
+				jobFailed: true,
+			}
+			// The failure of the last pod of the job is more useful
+			// than the reason of the job, e.g. BackoffLimitExceeded.
+			if hPod, err := p.getJobPod(context.Background(), hJob); err == nil {
+				if reason, message := getPodFailure(hPod); reason != "" {
+					jobErr.podName, jobErr.reason, jobErr.message = hPod.Name, reason, message
+				}
+				jobErr.exitCode = getPodExitCode(hPod)
+				jobErr.logs = p.getPodLogs(context.Background(), hPod, failedPodLogLines)
+			}
+			return false, jobErr
+		}
+		return false, nil
+	})
+	return err
+}
+
+// watchJobPods returns with an error when a pod of the helper job cannot
+// be started, as such a pod is not retried by the job. It returns when
+// the context is done otherwise.
+func (p *Provisioner) watchJobPods(ctx context.Context, hJob *batchv1.Job) error {
+	podClient := p.kubeClient.CoreV1().Pods(p.getHelperNamespace())
+	selector := jobPodSelector(hJob)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = selector.String()
+			return podClient.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = selector.String()
+			return podClient.Watch(ctx, options)
+		},
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		checkPod, ok := event.Object.(*corev1.Pod)
+		if !ok || event.Type == watch.Deleted || !selector.Matches(labels.Set(checkPod.Labels)) {
+			return false, nil
+		}
+		if reason, message := getPodStartFailure(checkPod); reason != "" {
+			return false, &helperPodError{
+				podName: checkPod.Name,
+				reason:  reason,
+				message: message,
+				logs:    p.getPodLogs(context.Background(), checkPod, failedPodLogLines),
+			}
+		}
+		return false, nil
+	})
+	return err
+}
+
+// reportJobProgress calls the progress function every progressInterval
+// with the last line of the logs of the pod of the helper job, till the
+// context is cancelled.
+func (p *Provisioner) reportJobProgress(ctx context.Context, hJob *batchv1.Job, progress func(string)) {
+	lastProgress := ""
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		hPod, err := p.getJobPod(ctx, hJob)
+		if err != nil {
+			return
+		}
+		if line := p.getPodLogs(ctx, hPod, 1); line != "" && line != lastProgress {
+			progress(line)
+			lastProgress = line
+		}
+	}, progressInterval)
+}
+
+// jobPodSelector returns the selector of the pods of the helper job. The
+// selector set by the job controller matches the UID of the job, so the
+// pods of a replaced job with the same name are not selected.
+func jobPodSelector(hJob *batchv1.Job) labels.Selector {
+	if hJob.Spec.Selector != nil {
+		if selector, err := metav1.LabelSelectorAsSelector(hJob.Spec.Selector); err == nil {
+			return selector
+		}
+	}
+	return labels.SelectorFromSet(labels.Set{"job-name": hJob.Name})
+}
+
+// getJobPod returns the most recently created pod of the helper job.
+func (p *Provisioner) getJobPod(ctx context.Context, hJob *batchv1.Job) (*corev1.Pod, error) {
+	pods, err := p.kubeClient.CoreV1().Pods(p.getHelperNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: jobPodSelector(hJob).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pods of helper job %v", hJob.Name)
+	}
+	var hPod *corev1.Pod
+	for i := range pods.Items {
+		if hPod == nil || hPod.CreationTimestamp.Before(&pods.Items[i].CreationTimestamp) {
+			hPod = &pods.Items[i]
+		}
+	}
+	if hPod == nil {
+		return nil, errors.Errorf("no pod found for helper job %v", hJob.Name)
+	}
+	return hPod, nil
+}
+
+// getPodLogs returns the last lines of the logs of the helper pod, or
+// empty logs if the logs cannot be read.
+func (p *Provisioner) getPodLogs(ctx context.Context, hPod *corev1.Pod, tailLines int64) string {
+	logs, err := p.kubeClient.CoreV1().Pods(p.getHelperNamespace()).GetLogs(hPod.Name, &corev1.PodLogOptions{TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		klog.V(4).Infof("unable to get logs of the helper pod %v: %v", hPod.Name, err)
+		return ""
+	}
+	return strings.TrimSpace(string(logs))
+}
+
+// getJobCondition returns the condition of the job of the given type, if
+// it is true.
+func getJobCondition(hJob *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i := range hJob.Status.Conditions {
+		condition := &hJob.Status.Conditions[i]
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return condition
+		}
+	}
+	return nil
+}
+
+// helperPodError is returned if a helper pod fails. It has the reason of
+// the failure, the termination message of the helper container, and the
+// last lines of its logs.
+type helperPodError struct {
+	podName string
+	reason  string
+	message string
+	logs    string
+	// exitCode is the exit code of the helper container, if it
+	// exited with an error.
+	exitCode int32
+	// jobFailed is set if the helper job has failed, rather than its
+	// pod failing to start.
+	jobFailed bool
+}
+
+func (e *helperPodError) Error() string {
+	msg := fmt.Sprintf("helper pod %v failed: %v", e.podName, e.reason)
+	if e.message != "" {
+		msg += ": " + e.message
+	}
+	if e.logs != "" {
+		msg += ", logs: " + e.logs
+	}
+	return msg
+}
+
+// recordHelperPodFailure emits an event with the reason, termination
+// message and logs of the failed helper pod, if the error is from a
+// helper pod.
+func (p *Provisioner) recordHelperPodFailure(obj runtime.Object, err error) {
+	podErr, ok := errors.Cause(err).(*helperPodError)
+	if !ok {
+		return
+	}
+	alertlog.Logger.Errorw("",
+		"eventcode", eventCodeHelperFailure,
+		"msg", "Helper pod failed",
+		"rname", podErr.podName,
+		"reason", podErr.reason,
+	)
+	p.recordEvent(obj, corev1.EventTypeWarning, eventCodeHelperFailure, "%v", podErr)
+	countFailure(eventCodeHelperFailure, podErr.reason)
+}
+
+// getPodFailure returns the reason and message of the failure of the
+// helper pod, or an empty reason if the pod has not failed. A pod fails
+// if it exits with an error, or if it cannot be started.
+func getPodFailure(hPod *corev1.Pod) (string, string) {
+	for _, status := range hPod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Sprintf("container %v exited with code %v", status.Name, terminated.ExitCode),
+				strings.TrimSpace(terminated.Message)
+		}
+	}
+	if hPod.Status.Phase == corev1.PodFailed {
+		return string(corev1.PodFailed), hPod.Status.Message
+	}
+	return getPodStartFailure(hPod)
+}
+
+// getPodExitCode returns the exit code of the helper container, or 0 if
+// the container has not exited with an error.
+func getPodExitCode(hPod *corev1.Pod) int32 {
+	for _, status := range hPod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return terminated.ExitCode
+		}
+	}
+	return 0
+}
+
+// getPodStartFailure returns the reason and message of the failure to
+// start the helper pod, or an empty reason if the pod has not failed to
+// start. A pod fails to start if its image cannot be pulled, or if it
+// cannot be scheduled.
+func getPodStartFailure(hPod *corev1.Pod) (string, string) {
+	for _, status := range hPod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ImagePullBackOff", "ErrImagePull", "InvalidImageName",
+				"CreateContainerConfigError", "CreateContainerError":
+				return waiting.Reason, waiting.Message
+			}
+		}
+	}
+	for _, condition := range hPod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			return condition.Reason, condition.Message
+		}
+	}
+	return "", ""
+}
+
+// GarbageCollectHelperPods deletes the helper jobs and pods left behind
+// by a previous run of the provisioner. These are
+//   - the helper jobs that completed more than helperPodGCGracePeriod ago,
+//   - the helper jobs of a PVC, whose PVC and PV no longer exist, even if
+//     they are running or failed, as no operation will take their result,
+//   - the bare helper pods launched by the versions of the provisioner
+//     before the helper jobs, that have no owner.
+//
+// Other running helper jobs are adopted when their operation is retried,
+// and other failed helper jobs are kept for inspection till their
+// ttlSecondsAfterFinished.
+func (p *Provisioner) GarbageCollectHelperPods(ctx context.Context) error {
+	jobs, err := p.kubeClient.BatchV1().Jobs(p.getHelperNamespace()).List(ctx, metav1.ListOptions{
+		LabelSelector: helperPodLabel + "=true",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list helper jobs")
+	}
+	now := time.Now()
+	for i := range jobs.Items {
+		hJob := &jobs.Items[i]
+		if hJob.DeletionTimestamp != nil {
+			continue
+		}
+		if isOrphanedHelperJob(hJob, now) {
+			klog.Infof("Deleting completed helper job %v", hJob.Name)
+			p.deleteHelperJob(ctx, hJob, nil)
+			continue
+		}
+		gone, err := p.isHelperJobVolumeGone(ctx, hJob)
+		if err != nil {
+			klog.Errorf("Failed to get the volume of helper job %v: %v", hJob.Name, err)
+			continue
+		}
+		if gone {
+			klog.Infof("Deleting helper job %v of a deleted volume", hJob.Name)
+			p.deleteHelperJob(ctx, hJob, nil)
+		}
+	}
+
+	// The helper pods were launched in the namespace of the provisioner
+	// before the helper namespace could be set.
+	namespaces := []string{p.getHelperNamespace()}
+	if p.namespace != p.getHelperNamespace() {
+		namespaces = append(namespaces, p.namespace)
+	}
+	for _, namespace := range namespaces {
+		pods, err := p.kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to list helper pods")
+		}
+		for i := range pods.Items {
+			hPod := &pods.Items[i]
+			if hPod.DeletionTimestamp != nil || !isLegacyHelperPod(hPod) {
+				continue
+			}
+			klog.Infof("Deleting helper pod %v/%v of a previous version", hPod.Namespace, hPod.Name)
+			err := p.kubeClient.CoreV1().Pods(namespace).Delete(ctx, hPod.Name, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				klog.Errorf("unable to delete the helper pod: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// isOrphanedHelperJob returns true if the helper job completed more than
+// helperPodGCGracePeriod ago.
+func isOrphanedHelperJob(hJob *batchv1.Job, now time.Time) bool {
+	if hJob.DeletionTimestamp != nil {
+		return false
+	}
+	condition := getJobCondition(hJob, batchv1.JobComplete)
+	if condition == nil {
+		return false
+	}
+	return now.Sub(condition.LastTransitionTime.Time) > helperPodGCGracePeriod
+}
+
+// isHelperJobVolumeGone returns true if the helper job is of a PVC, and
+// neither the PVC nor the PV of the job exist. The helper jobs without
+// a PVC, e.g. of a trashed volume, are not tied to the volume objects.
+func (p *Provisioner) isHelperJobVolumeGone(ctx context.Context, hJob *batchv1.Job) (bool, error) {
+	pvcName, ok := hJob.Labels[helperPVCLabel]
+	if !ok {
+		return false, nil
+	}
+	_, err := p.kubeClient.CoreV1().PersistentVolumeClaims(hJob.Labels[helperPVCNamespaceLabel]).Get(ctx, pvcName, metav1.GetOptions{})
+	if err == nil || !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	if pvName, ok := hJob.Labels[helperPVLabel]; ok {
+		_, err = p.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+		if err == nil || !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// legacyHelperPodName matches the names of the helper pods launched by
+// the versions of the provisioner before the helper jobs, i.e. the
+// operation followed by the PV name.
+var legacyHelperPodName = regexp.MustCompile(`^(init|cleanup|quota)-pvc-.+$`)
+
+// isLegacyHelperPod returns true if the pod is a bare helper pod of a
+// previous version of the provisioner. Such pods have no owner, no
+// helper labels, and run the local-path container of their operation.
+func isLegacyHelperPod(hPod *corev1.Pod) bool {
+	match := legacyHelperPodName.FindStringSubmatch(hPod.Name)
+	if match == nil || len(hPod.OwnerReferences) > 0 {
+		return false
+	}
+	if _, ok := hPod.Labels[helperPodLabel]; ok {
+		return false
+	}
+	for _, container := range hPod.Spec.Containers {
+		if container.Name == "local-path-"+match[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHelperLabels(t *testing.T) {
+	config := podConfig{
+		podName: "init",
+		pOpts: &HelperPodOptions{
+			pvName:       "pvc-0001",
+			pvcName:      strings.Repeat("a", 64),
+			pvcNamespace: "app",
+		},
+	}
+	want := map[string]string{
+		helperPodLabel:          "true",
+		helperOperationLabel:    "init",
+		helperPVLabel:           "pvc-0001",
+		helperPVCNamespaceLabel: "app",
+	}
+	if got := helperLabels(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("helperLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestNewHelperJob(t *testing.T) {
+	helperPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "cleanup-pvName",
+			Labels: map[string]string{helperPodLabel: "true"},
+		},
+		Spec: corev1.PodSpec{RestartPolicy: corev1.RestartPolicyNever},
+	}
+	hJob := newHelperJob(podConfig{timeoutCounts: WipeTimeoutCounts}, helperPod)
+	if hJob.Name != "cleanup-pvName" {
+		t.Errorf("newHelperJob() name = %v", hJob.Name)
+	}
+	if *hJob.Spec.BackoffLimit != helperJobBackoffLimit {
+		t.Errorf("newHelperJob() backoffLimit = %v", *hJob.Spec.BackoffLimit)
+	}
+	if *hJob.Spec.ActiveDeadlineSeconds != int64(WipeTimeoutCounts) {
+		t.Errorf("newHelperJob() activeDeadlineSeconds = %v", *hJob.Spec.ActiveDeadlineSeconds)
+	}
+	if *hJob.Spec.TTLSecondsAfterFinished != int32(defaultHelperJobTTL.Seconds()) {
+		t.Errorf("newHelperJob() ttlSecondsAfterFinished = %v", *hJob.Spec.TTLSecondsAfterFinished)
+	}
+	if hJob.Spec.Template.Labels[helperPodLabel] != "true" {
+		t.Errorf("newHelperJob() pod labels = %v", hJob.Spec.Template.Labels)
+	}
+
+	hJob = newHelperJob(podConfig{}, helperPod)
+	if *hJob.Spec.ActiveDeadlineSeconds != int64(CmdTimeoutCounts) {
+		t.Errorf("newHelperJob() default activeDeadlineSeconds = %v", *hJob.Spec.ActiveDeadlineSeconds)
+	}
+
+	noRetry := int32(0)
+	hJob = newHelperJob(podConfig{backoffLimit: &noRetry}, helperPod)
+	if *hJob.Spec.BackoffLimit != 0 {
+		t.Errorf("newHelperJob() backoffLimit = %v, want 0", *hJob.Spec.BackoffLimit)
+	}
+}
+
+func TestCreateHelperJob(t *testing.T) {
+	newJob := func(command string, condition batchv1.JobConditionType) *batchv1.Job {
+		hJob := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "init-pvName", Namespace: "openebs", UID: "existing"},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:    "local-path-init",
+							Command: []string{"/bin/sh", "-c", command},
+						}},
+					},
+				},
+			},
+		}
+		if condition != "" {
+			hJob.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+		}
+		return hJob
+	}
+
+	tests := map[string]struct {
+		existing    *batchv1.Job
+		expectAdopt bool
+	}{
+		"no existing job": {},
+		"running job": {
+			existing:    newJob("mkdir -m 0777 -p /data/pvName", ""),
+			expectAdopt: true,
+		},
+		"completed job": {
+			existing:    newJob("mkdir -m 0777 -p /data/pvName", batchv1.JobComplete),
+			expectAdopt: true,
+		},
+		"failed job": {
+			existing: newJob("mkdir -m 0777 -p /data/pvName", batchv1.JobFailed),
+		},
+		"job of another command": {
+			existing: newJob("rm -rf /data/pvName", ""),
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			objs := []runtime.Object{}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing)
+			}
+			p := &Provisioner{
+				kubeClient: fake.NewSimpleClientset(objs...),
+				namespace:  "openebs",
+			}
+			helperJob := newJob("mkdir -m 0777 -p /data/pvName", "")
+			helperJob.UID = ""
+			hJob, err := p.createHelperJob(context.Background(), helperJob)
+			if err != nil {
+				t.Fatalf("createHelperJob() unexpected error: %v", err)
+			}
+			if adopted := hJob.UID == "existing"; adopted != tt.expectAdopt {
+				t.Errorf("createHelperJob() adopted = %v, want %v", adopted, tt.expectAdopt)
+			}
+			got, err := p.kubeClient.BatchV1().Jobs("openebs").Get(context.Background(), "init-pvName", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("helper job not found: %v", err)
+			}
+			if command := got.Spec.Template.Spec.Containers[0].Command[2]; command != "mkdir -m 0777 -p /data/pvName" {
+				t.Errorf("helper job runs %q", command)
+			}
+		})
+	}
+}
+
+func TestWaitForJob(t *testing.T) {
+	tests := map[string]struct {
+		condition      batchv1.JobConditionType
+		podStatus      corev1.PodStatus
+		expectError    []string
+		expectExitCode int32
+		expectKept     bool
+	}{
+		"completed": {
+			condition: batchv1.JobComplete,
+			podStatus: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		"failed with termination message": {
+			condition: batchv1.JobFailed,
+			podStatus: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "local-path-init",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  "mkdir: permission denied",
+					}},
+				}},
+			},
+			expectError:    []string{"exited with code 1", "mkdir: permission denied", "fake logs"},
+			expectExitCode: 1,
+			expectKept:     true,
+		},
+		"image pull backoff": {
+			podStatus: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "local-path-init",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ImagePullBackOff",
+						Message: "Back-off pulling image",
+					}},
+				}},
+			},
+			expectError: []string{"ImagePullBackOff", "Back-off pulling image"},
+		},
+		"unschedulable": {
+			podStatus: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: "0/1 nodes are available",
+				}},
+			},
+			expectError: []string{"Unschedulable", "0/1 nodes are available"},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			hJob := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "init-pvName", Namespace: "openebs"},
+			}
+			if tt.condition != "" {
+				hJob.Status.Conditions = []batchv1.JobCondition{{Type: tt.condition, Status: corev1.ConditionTrue}}
+			}
+			hPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "init-pvName-abcde",
+					Namespace: "openebs",
+					Labels:    map[string]string{"job-name": "init-pvName"},
+				},
+				Status: tt.podStatus,
+			}
+			p := &Provisioner{
+				kubeClient: fake.NewSimpleClientset(hJob, hPod),
+				namespace:  "openebs",
+			}
+			err := p.exitPodWithTimeout(context.Background(), hJob, 5)
+			_, getErr := p.kubeClient.BatchV1().Jobs("openebs").Get(context.Background(), hJob.Name, metav1.GetOptions{})
+			if kept := getErr == nil; kept != tt.expectKept {
+				t.Errorf("exitPodWithTimeout() kept job = %v, want %v", kept, tt.expectKept)
+			}
+			if len(tt.expectError) == 0 {
+				if err != nil {
+					t.Fatalf("exitPodWithTimeout() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("exitPodWithTimeout() expected an error")
+			}
+			for _, want := range tt.expectError {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("exitPodWithTimeout() error %q does not contain %q", err, want)
+				}
+			}
+			if podErr, ok := errors.Cause(err).(*helperPodError); !ok || podErr.exitCode != tt.expectExitCode {
+				t.Errorf("exitPodWithTimeout() error %#v, want exit code %v", err, tt.expectExitCode)
+			}
+		})
+	}
+}
+
+func TestIsOrphanedHelperJob(t *testing.T) {
+	now := time.Now()
+	newJob := func(condition batchv1.JobConditionType, finishedAgo time.Duration) *batchv1.Job {
+		hJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "cleanup-pvName"}}
+		if condition != "" {
+			hJob.Status.Conditions = []batchv1.JobCondition{{
+				Type:               condition,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-finishedAgo)),
+			}}
+		}
+		return hJob
+	}
+	tests := map[string]struct {
+		hJob *batchv1.Job
+		want bool
+	}{
+		"running job": {
+			hJob: newJob("", 0),
+			want: false,
+		},
+		"recently completed job": {
+			hJob: newJob(batchv1.JobComplete, time.Minute),
+			want: false,
+		},
+		"completed job": {
+			hJob: newJob(batchv1.JobComplete, 10*time.Minute),
+			want: true,
+		},
+		"failed job": {
+			hJob: newJob(batchv1.JobFailed, 10*time.Minute),
+			want: false,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if got := isOrphanedHelperJob(tt.hJob, now); got != tt.want {
+				t.Errorf("isOrphanedHelperJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGarbageCollectHelperPods(t *testing.T) {
+	now := time.Now()
+	newJob := func(name string, pvcLabels bool, condition batchv1.JobConditionType) *batchv1.Job {
+		hJob := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-pvName",
+				Namespace: "openebs",
+				Labels: map[string]string{
+					helperPodLabel:       "true",
+					helperOperationLabel: name,
+					helperPVLabel:        "pvName",
+				},
+			},
+		}
+		if pvcLabels {
+			hJob.Labels[helperPVCLabel] = "pvcName"
+			hJob.Labels[helperPVCNamespaceLabel] = "default"
+		}
+		if condition != "" {
+			hJob.Status.Conditions = []batchv1.JobCondition{{
+				Type:               condition,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute)),
+			}}
+		}
+		return hJob
+	}
+	newPod := func(name, container string, owned bool) *corev1.Pod {
+		hPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openebs"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: container}},
+			},
+		}
+		if owned {
+			hPod.OwnerReferences = []metav1.OwnerReference{{Kind: "Job", Name: name}}
+		}
+		return hPod
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvcName", Namespace: "default"}}
+	pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvName"}}
+
+	tests := map[string]struct {
+		objects    []runtime.Object
+		expectJobs []string
+		expectPods []string
+	}{
+		"running job of an existing pvc": {
+			objects:    []runtime.Object{pvc, newJob("init", true, "")},
+			expectJobs: []string{"init-pvName"},
+		},
+		"running job of an existing pv": {
+			objects:    []runtime.Object{pv, newJob("cleanup", true, "")},
+			expectJobs: []string{"cleanup-pvName"},
+		},
+		"running job of a deleted volume": {
+			objects: []runtime.Object{newJob("init", true, "")},
+		},
+		"failed job of a deleted volume": {
+			objects: []runtime.Object{newJob("quota", true, batchv1.JobFailed)},
+		},
+		"failed job of an existing volume": {
+			objects:    []runtime.Object{pvc, newJob("quota", true, batchv1.JobFailed)},
+			expectJobs: []string{"quota-pvName"},
+		},
+		"completed job of an existing volume": {
+			objects: []runtime.Object{pvc, newJob("init", true, batchv1.JobComplete)},
+		},
+		"running job without a pvc": {
+			objects:    []runtime.Object{newJob("cleanup", false, "")},
+			expectJobs: []string{"cleanup-pvName"},
+		},
+		"legacy helper pods": {
+			objects: []runtime.Object{
+				newPod("init-pvc-1", "local-path-init", false),
+				newPod("cleanup-pvc-2", "local-path-cleanup", false),
+				newPod("quota-pvc-3", "local-path-quota", false),
+			},
+		},
+		"pods of helper jobs and other pods": {
+			objects: []runtime.Object{
+				newPod("init-pvc-1", "local-path-init", true),
+				newPod("init-pvc-2", "app", false),
+				newPod("wipe-pvc-3", "local-path-wipe", false),
+			},
+			expectPods: []string{"init-pvc-1", "init-pvc-2", "wipe-pvc-3"},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+			p := &Provisioner{kubeClient: client, namespace: "openebs"}
+			if err := p.GarbageCollectHelperPods(context.Background()); err != nil {
+				t.Fatalf("GarbageCollectHelperPods() error %v", err)
+			}
+
+			jobs, _ := client.BatchV1().Jobs("openebs").List(context.Background(), metav1.ListOptions{})
+			var gotJobs []string
+			for _, hJob := range jobs.Items {
+				gotJobs = append(gotJobs, hJob.Name)
+			}
+			if !reflect.DeepEqual(gotJobs, tt.expectJobs) {
+				t.Errorf("GarbageCollectHelperPods() kept jobs %v, want %v", gotJobs, tt.expectJobs)
+			}
+			pods, _ := client.CoreV1().Pods("openebs").List(context.Background(), metav1.ListOptions{})
+			var gotPods []string
+			for _, hPod := range pods.Items {
+				gotPods = append(gotPods, hPod.Name)
+			}
+			if !reflect.DeepEqual(gotPods, tt.expectPods) {
+				t.Errorf("GarbageCollectHelperPods() kept pods %v, want %v", gotPods, tt.expectPods)
+			}
+		})
+	}
+}
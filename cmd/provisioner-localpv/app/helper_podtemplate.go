@@ -0,0 +1,119 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// helperPodTemplateKey is the key of the ConfigMap of a helper pod
+	// template that holds the template.
+	helperPodTemplateKey = "template"
+)
+
+// helperPodTemplate holds the settings merged into the helper pods. It
+// is read from the template key of a ConfigMap in the namespace of the
+// provisioner, e.g.
+//
+//	labels:
+//	  team: storage
+//	priorityClassName: system-node-critical
+//	resources:
+//	  requests:
+//	    cpu: 10m
+//	    memory: 32Mi
+//	tolerations:
+//	- key: dedicated
+//	  operator: Exists
+type helperPodTemplate struct {
+	Labels            map[string]string           `json:"labels,omitempty"`
+	Annotations       map[string]string           `json:"annotations,omitempty"`
+	Resources         corev1.ResourceRequirements `json:"resources,omitempty"`
+	PriorityClassName string                      `json:"priorityClassName,omitempty"`
+	Tolerations       []corev1.Toleration         `json:"tolerations,omitempty"`
+}
+
+// parseHelperPodTemplate parses the helper pod template. Unknown fields
+// are rejected, so that a misspelt setting is not silently ignored.
+func parseHelperPodTemplate(data string) (*helperPodTemplate, error) {
+	template := &helperPodTemplate{}
+	if err := yaml.UnmarshalStrict([]byte(data), template); err != nil {
+		return nil, errors.Wrap(err, "invalid helper pod template")
+	}
+	return template, nil
+}
+
+// getHelperPodTemplate returns the helper pod template of the ConfigMap
+// in the namespace of the provisioner. The name of the StorageClass
+// template takes precedence over the default template set with the
+// OPENEBS_IO_HELPER_POD_TEMPLATE environment variable. No template is
+// returned if neither is set.
+func (p *Provisioner) getHelperPodTemplate(ctx context.Context, name string) (*helperPodTemplate, error) {
+	if name == "" {
+		name = getHelperPodTemplateName()
+	}
+	if name == "" {
+		return nil, nil
+	}
+	configMap, err := p.kubeClient.CoreV1().ConfigMaps(p.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get helper pod template %v", name)
+	}
+	template, err := parseHelperPodTemplate(configMap.Data[helperPodTemplateKey])
+	if err != nil {
+		return nil, errors.Wrapf(err, "configmap %v", name)
+	}
+	return template, nil
+}
+
+// apply merges the template into the helper pod. The labels of the
+// helper pod take precedence over the labels of the template, and the
+// tolerations of the template are added to the tolerations for the
+// taints of the node.
+func (t *helperPodTemplate) apply(hPod *corev1.Pod) {
+	if t == nil {
+		return
+	}
+	for key, value := range t.Labels {
+		if _, ok := hPod.Labels[key]; ok {
+			continue
+		}
+		if hPod.Labels == nil {
+			hPod.Labels = map[string]string{}
+		}
+		hPod.Labels[key] = value
+	}
+	for key, value := range t.Annotations {
+		if hPod.Annotations == nil {
+			hPod.Annotations = map[string]string{}
+		}
+		hPod.Annotations[key] = value
+	}
+	if t.PriorityClassName != "" {
+		hPod.Spec.PriorityClassName = t.PriorityClassName
+	}
+	hPod.Spec.Tolerations = append(hPod.Spec.Tolerations, t.Tolerations...)
+	for i := range hPod.Spec.Containers {
+		hPod.Spec.Containers[i].Resources = t.Resources
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseHelperPodTemplate(t *testing.T) {
+	testCases := map[string]struct {
+		data           string
+		expectTemplate *helperPodTemplate
+		expectError    bool
+	}{
+		"empty": {
+			data:           "",
+			expectTemplate: &helperPodTemplate{},
+		},
+		"all settings": {
+			data: `
+labels:
+  team: storage
+annotations:
+  example.com/audit: "true"
+priorityClassName: system-node-critical
+resources:
+  requests:
+    cpu: 10m
+tolerations:
+- key: dedicated
+  operator: Exists
+`,
+			expectTemplate: &helperPodTemplate{
+				Labels:            map[string]string{"team": "storage"},
+				Annotations:       map[string]string{"example.com/audit": "true"},
+				PriorityClassName: "system-node-critical",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+				},
+				Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+			},
+		},
+		"unknown field": {
+			data:        "priorityClass: system-node-critical",
+			expectError: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			template, err := parseHelperPodTemplate(v.data)
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got template %v", template)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if !reflect.DeepEqual(template, v.expectTemplate) {
+				t.Errorf("expected template %+v, but got %+v", v.expectTemplate, template)
+			}
+		})
+	}
+}
+
+func TestHelperPodTemplateApply(t *testing.T) {
+	template := &helperPodTemplate{
+		Labels:            map[string]string{"team": "storage", helperPodLabel: "false"},
+		Annotations:       map[string]string{"example.com/audit": "true"},
+		PriorityClassName: "system-node-critical",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+		},
+		Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+	}
+	hPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{helperPodLabel: "true"}},
+		Spec: corev1.PodSpec{
+			Tolerations: []corev1.Toleration{{Key: "node.kubernetes.io/disk-pressure", Operator: corev1.TolerationOpExists}},
+			Containers:  []corev1.Container{{Name: "local-path-init"}},
+		},
+	}
+	template.apply(hPod)
+
+	if hPod.Labels[helperPodLabel] != "true" || hPod.Labels["team"] != "storage" {
+		t.Errorf("unexpected labels %v", hPod.Labels)
+	}
+	if hPod.Annotations["example.com/audit"] != "true" {
+		t.Errorf("unexpected annotations %v", hPod.Annotations)
+	}
+	if hPod.Spec.PriorityClassName != "system-node-critical" {
+		t.Errorf("unexpected priorityClassName %v", hPod.Spec.PriorityClassName)
+	}
+	if len(hPod.Spec.Tolerations) != 2 {
+		t.Errorf("unexpected tolerations %v", hPod.Spec.Tolerations)
+	}
+	if !reflect.DeepEqual(hPod.Spec.Containers[0].Resources, template.Resources) {
+		t.Errorf("unexpected resources %v", hPod.Spec.Containers[0].Resources)
+	}
+}
+
+func TestGetHelperPodTemplate(t *testing.T) {
+	newConfigMap := func(name, data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openebs"},
+			Data:       map[string]string{helperPodTemplateKey: data},
+		}
+	}
+	p := &Provisioner{
+		kubeClient: fake.NewSimpleClientset(
+			newConfigMap("default-template", "priorityClassName: default"),
+			newConfigMap("sc-template", "priorityClassName: sc"),
+		),
+		namespace: "openebs",
+	}
+
+	testCases := map[string]struct {
+		defaultName    string
+		name           string
+		expectPriority string
+		expectNil      bool
+		expectError    bool
+	}{
+		"no template":                   {expectNil: true},
+		"default template":              {defaultName: "default-template", expectPriority: "default"},
+		"storageclass template":         {defaultName: "default-template", name: "sc-template", expectPriority: "sc"},
+		"missing storageclass template": {name: "missing", expectError: true},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			os.Setenv(string(ProvisionerHelperPodTemplate), v.defaultName)
+			defer os.Unsetenv(string(ProvisionerHelperPodTemplate))
+
+			template, err := p.getHelperPodTemplate(context.Background(), v.name)
+			if v.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but got template %v", template)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if v.expectNil {
+				if template != nil {
+					t.Errorf("expected no template, but got %v", template)
+				}
+				return
+			}
+			if template.PriorityClassName != v.expectPriority {
+				t.Errorf("expected priorityClassName %v, but got %v", v.expectPriority, template.PriorityClassName)
+			}
+		})
+	}
+}
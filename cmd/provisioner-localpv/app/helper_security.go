@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// helperPodSecurity is the privileges with which the helper pod of an
+// operation is launched.
+type helperPodSecurity struct {
+	// privileged launches the helper pod in privileged mode, e.g. to
+	// write to a block device or to relabel a directory.
+	privileged bool
+	// capabilities are added to the helper container, after dropping
+	// all the other capabilities.
+	capabilities []corev1.Capability
+	// mountDev mounts the /dev of the node in the helper pod.
+	mountDev bool
+}
+
+var (
+	// dacHelperPod can create, modify and remove the files and
+	// directories of any user, e.g. to initialize or clean up a volume.
+	dacHelperPod = helperPodSecurity{
+		capabilities: []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID"},
+	}
+
+	// quotaHelperPod can also set the project quota of a directory. The
+	// quota tools look up the block device of the filesystem in /dev.
+	quotaHelperPod = helperPodSecurity{
+		capabilities: []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "SYS_ADMIN"},
+		mountDev:     true,
+	}
+
+	// relabelHelperPod can also change the SELinux context of the
+	// files of the node.
+	relabelHelperPod = helperPodSecurity{
+		privileged: true,
+	}
+
+	// deviceHelperPod can write to the block devices of the node.
+	deviceHelperPod = helperPodSecurity{
+		privileged: true,
+		mountDev:   true,
+	}
+
+	// privilegedHelperPod is used for all the helper pods if
+	// OPENEBS_IO_HELPER_POD_PRIVILEGED is set, e.g. on nodes where a
+	// container cannot write to the host directories without it.
+	privilegedHelperPod = helperPodSecurity{
+		privileged: true,
+		mountDev:   true,
+	}
+)
+
+// securityContext returns the security context of the helper container.
+// An unprivileged helper container drops all the capabilities other
+// than the ones it needs, and cannot gain more privileges.
+func (s helperPodSecurity) securityContext() *corev1.SecurityContext {
+	if s.privileged {
+		privileged := true
+		return &corev1.SecurityContext{Privileged: &privileged}
+	}
+	privileged, allowPrivilegeEscalation := false, false
+	return &corev1.SecurityContext{
+		Privileged:               &privileged,
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+			Add:  s.capabilities,
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
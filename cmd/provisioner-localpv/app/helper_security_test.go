@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHelperPodSecurityContext(t *testing.T) {
+	testCases := map[string]struct {
+		security         helperPodSecurity
+		expectPrivileged bool
+		expectAdd        []corev1.Capability
+	}{
+		"dac": {
+			security:  dacHelperPod,
+			expectAdd: []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID"},
+		},
+		"quota": {
+			security:  quotaHelperPod,
+			expectAdd: []corev1.Capability{"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "SYS_ADMIN"},
+		},
+		"no capabilities": {
+			security: helperPodSecurity{},
+		},
+		"device": {
+			security:         deviceHelperPod,
+			expectPrivileged: true,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			sc := v.security.securityContext()
+			if sc.Privileged == nil || *sc.Privileged != v.expectPrivileged {
+				t.Fatalf("expected privileged %v, but got %v", v.expectPrivileged, sc.Privileged)
+			}
+			if v.expectPrivileged {
+				return
+			}
+			if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+				t.Errorf("expected privilege escalation to be disallowed")
+			}
+			if !reflect.DeepEqual(sc.Capabilities.Drop, []corev1.Capability{"ALL"}) {
+				t.Errorf("expected all capabilities to be dropped, but got %v", sc.Capabilities.Drop)
+			}
+			if !reflect.DeepEqual(sc.Capabilities.Add, v.expectAdd) {
+				t.Errorf("expected capabilities %v, but got %v", v.expectAdd, sc.Capabilities.Add)
+			}
+			if sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+				t.Errorf("expected RuntimeDefault seccomp profile, but got %v", sc.SeccompProfile)
+			}
+		})
+	}
+}
+
+func TestLaunchPodSecurity(t *testing.T) {
+	testCases := map[string]struct {
+		security         helperPodSecurity
+		helperNamespace  string
+		privilegedEnv    string
+		expectNamespace  string
+		expectSA         string
+		expectDev        bool
+		expectPrivileged bool
+	}{
+		"dac helper pod": {
+			security:        dacHelperPod,
+			expectNamespace: "openebs",
+			expectSA:        "openebs-localpv",
+		},
+		"device helper pod": {
+			security:         deviceHelperPod,
+			expectNamespace:  "openebs",
+			expectSA:         "openebs-localpv",
+			expectDev:        true,
+			expectPrivileged: true,
+		},
+		"privileged override": {
+			security:         dacHelperPod,
+			privilegedEnv:    "true",
+			expectNamespace:  "openebs",
+			expectSA:         "openebs-localpv",
+			expectDev:        true,
+			expectPrivileged: true,
+		},
+		"helper namespace": {
+			security:        dacHelperPod,
+			helperNamespace: "openebs-helpers",
+			expectNamespace: "openebs-helpers",
+			expectSA:        "default",
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			os.Setenv(string(ProvisionerHelperPodPrivileged), v.privilegedEnv)
+			defer os.Unsetenv(string(ProvisionerHelperPodPrivileged))
+
+			p := &Provisioner{
+				kubeClient:      fake.NewSimpleClientset(),
+				namespace:       "openebs",
+				helperNamespace: v.helperNamespace,
+				helperImage:     "openebs/linux-utils:latest",
+			}
+			config := podConfig{
+				podName:   "cleanup",
+				parentDir: "/var/openebs/local",
+				security:  v.security,
+				pOpts: &HelperPodOptions{
+					name:               "pvc-0001",
+					pvName:             "pvc-0001",
+					serviceAccountName: "openebs-localpv",
+					nodeAffinityLabels: map[string]string{"kubernetes.io/hostname": "node-1"},
+					cmdsForPath:        []string{"rm", "-rf"},
+				},
+			}
+			hJob, err := p.launchPod(context.Background(), config)
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if hJob.Namespace != v.expectNamespace {
+				t.Errorf("expected namespace %v, but got %v", v.expectNamespace, hJob.Namespace)
+			}
+
+			podSpec := hJob.Spec.Template.Spec
+			if podSpec.ServiceAccountName != v.expectSA {
+				t.Errorf("expected service account %v, but got %v", v.expectSA, podSpec.ServiceAccountName)
+			}
+			if podSpec.AutomountServiceAccountToken == nil || *podSpec.AutomountServiceAccountToken {
+				t.Errorf("expected the service account token not to be mounted")
+			}
+			hasDev := false
+			for _, vol := range podSpec.Volumes {
+				if vol.Name == "dev" {
+					hasDev = true
+				}
+			}
+			if hasDev != v.expectDev {
+				t.Errorf("expected dev volume %v, but got %v", v.expectDev, hasDev)
+			}
+			sc := podSpec.Containers[0].SecurityContext
+			if sc == nil || sc.Privileged == nil || *sc.Privileged != v.expectPrivileged {
+				t.Errorf("expected privileged %v, but got %v", v.expectPrivileged, sc)
+			}
+		})
+	}
+}
@@ -96,3 +96,122 @@ func TestConvertToK(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractSubPath(t *testing.T) {
+	tests := map[string]struct {
+		path          string
+		rootPath      string
+		wantParentDir string
+		wantVolumeDir string
+		wantErr       bool
+	}{
+		"without root path": {
+			path:          "/var/openebs/local/pvName",
+			wantParentDir: "/var/openebs/local",
+			wantVolumeDir: "pvName",
+		},
+		"with root path": {
+			path:          "/var/openebs/local/ns/pvcName",
+			rootPath:      "/var/openebs/local",
+			wantParentDir: "/var/openebs/local",
+			wantVolumeDir: "ns/pvcName",
+		},
+		"path outside root path": {
+			path:     "/var/openebs/other/pvName",
+			rootPath: "/var/openebs/local",
+			wantErr:  true,
+		},
+		"path under root directory": {
+			path:    "/pvName",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			pOpts := &HelperPodOptions{path: tt.path, rootPath: tt.rootPath}
+			parentDir, volumeDir, err := pOpts.extractSubPath()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractSubPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if parentDir != tt.wantParentDir || volumeDir != tt.wantVolumeDir {
+				t.Errorf("extractSubPath() = %v, %v, want %v, %v", parentDir, volumeDir, tt.wantParentDir, tt.wantVolumeDir)
+			}
+		})
+	}
+}
+
+func TestVolumeDirPermissionsCmd(t *testing.T) {
+	tests := map[string]struct {
+		pOpts *HelperPodOptions
+		want  string
+	}{
+		"no permissions": {
+			pOpts: &HelperPodOptions{},
+			want:  "",
+		},
+		"mode": {
+			pOpts: &HelperPodOptions{dirMode: "0750"},
+			want:  " && chmod 0750 /data/pvName",
+		},
+		"owner and group": {
+			pOpts: &HelperPodOptions{dirUID: "1000", dirGID: "2000"},
+			want:  " && chown 1000:2000 /data/pvName",
+		},
+		"group only": {
+			pOpts: &HelperPodOptions{dirGID: "2000"},
+			want:  " && chown :2000 /data/pvName",
+		},
+		"all": {
+			pOpts: &HelperPodOptions{dirMode: "2770", dirUID: "1000", dirDefaultACL: "g::rwx,o::-"},
+			want:  " && chmod 2770 /data/pvName && chown 1000 /data/pvName && setfacl -d -m g::rwx,o::- /data/pvName",
+		},
+		"seeded": {
+			pOpts: &HelperPodOptions{dirMode: "2770", dirUID: "1000", seedSecret: "seed-pvName"},
+			want: " && find /data/pvName -type d -exec chmod 2770 {} + && find /data/pvName -type f -exec chmod 0660 {} +" +
+				" && chown -R 1000 /data/pvName",
+		},
+		"selinux context": {
+			pOpts: &HelperPodOptions{selinuxContext: "system_u:object_r:container_file_t:s0"},
+			want: " && if [ -e /sys/fs/selinux/enforce ]; then chcon -R system_u:object_r:container_file_t:s0 /data/pvName; " +
+				"else echo \"SELinux is disabled, skipping relabel of /data/pvName\"; fi",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if got := volumeDirPermissionsCmd(tt.pOpts, "/data/pvName"); got != tt.want {
+				t.Errorf("volumeDirPermissionsCmd() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeedCmd(t *testing.T) {
+	tests := map[string]struct {
+		pOpts *HelperPodOptions
+		want  string
+	}{
+		"no seed": {
+			pOpts: &HelperPodOptions{},
+			want:  "",
+		},
+		"secret": {
+			pOpts: &HelperPodOptions{seedSecret: "seed-pvName"},
+			want: " && if [ -z \"$(ls -A /data/pvName)\" ]; then " +
+				"for f in /seed/*; do if [ -f \"$f\" ]; then cp -L \"$f\" /data/pvName/ || exit 1; fi; done; fi",
+		},
+		"tarball": {
+			pOpts: &HelperPodOptions{seedTarball: "/var/openebs/seeds/app.tar"},
+			want:  " && if [ -z \"$(ls -A /data/pvName)\" ]; then tar -xf /seed.tar -C /data/pvName; fi",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			if got := seedCmd(tt.pOpts, "/data/pvName"); got != tt.want {
+				t.Errorf("seedCmd() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
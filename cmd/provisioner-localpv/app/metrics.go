@@ -0,0 +1,184 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	metricsNamespace = "openebs_localpv"
+
+	// metricsPath is the path at which the metrics are served.
+	metricsPath = "/metrics"
+)
+
+var (
+	// provisionDuration is the time taken to provision a volume, by
+	// StorageType.
+	provisionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "provision_duration_seconds",
+			Help:      "Time taken to provision a volume.",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{"storage_type"},
+	)
+
+	// deleteDuration is the time taken to delete a volume, by
+	// StorageType.
+	deleteDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "delete_duration_seconds",
+			Help:      "Time taken to delete a volume.",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 3600},
+		},
+		[]string{"storage_type"},
+	)
+
+	// helperPodDuration is the time taken by a helper pod, from the
+	// creation of its job until it finishes, by operation.
+	helperPodDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "helper_pod_duration_seconds",
+			Help:      "Time taken by a helper pod.",
+			Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 3600},
+		},
+		[]string{"operation"},
+	)
+
+	// failures counts the failures logged to the alertlog, by event
+	// code and reason.
+	failures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "failures_total",
+			Help:      "Number of failures, by event code and reason.",
+		},
+		[]string{"event_code", "reason"},
+	)
+
+	// inFlightHelperPods is the number of helper pods being waited on,
+	// by node.
+	inFlightHelperPods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "helper_pods_in_flight",
+			Help:      "Number of running helper pods.",
+		},
+		[]string{"node"},
+	)
+
+	// leader is 1 while the controllers run on this replica, i.e.
+	// while it holds the leader lease if leader election is enabled.
+	leader = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "leader",
+			Help:      "1 if the controllers run on this replica, else 0.",
+		},
+	)
+
+	// metricsRegistry holds the metrics of the provisioner, along with
+	// the metrics of the Go runtime and of the process.
+	metricsRegistry = newMetricsRegistry()
+)
+
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		provisionDuration,
+		deleteDuration,
+		helperPodDuration,
+		failures,
+		inFlightHelperPods,
+		leader,
+	)
+	return registry
+}
+
+// observeDuration records the time since start in the histogram.
+func observeDuration(histogram *prometheus.HistogramVec, label string, start time.Time) {
+	histogram.WithLabelValues(label).Observe(time.Since(start).Seconds())
+}
+
+// countFailure counts a failure logged to the alertlog with the event
+// code and reason.
+func countFailure(eventCode, reason string) {
+	failures.WithLabelValues(eventCode, reason).Inc()
+}
+
+// observeHelperJob tracks the helper job as in flight on its node until
+// the returned func is called, which also records the time taken by the
+// helper job. The time is measured from the creation of the job, so that
+// an adopted job is accounted for in full.
+func observeHelperJob(hJob *batchv1.Job) func() {
+	node := helperJobNode(hJob)
+	operation := hJob.Labels[helperOperationLabel]
+	start := hJob.CreationTimestamp.Time
+	if start.IsZero() {
+		start = time.Now()
+	}
+	inFlightHelperPods.WithLabelValues(node).Inc()
+	return func() {
+		inFlightHelperPods.WithLabelValues(node).Dec()
+		observeDuration(helperPodDuration, operation, start)
+	}
+}
+
+// helperJobNode returns the node of the helper job, from the hostname
+// in the node affinity of its pod, or else the first value of the node
+// affinity.
+func helperJobNode(hJob *batchv1.Job) string {
+	affinity := hJob.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	node := ""
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Operator != corev1.NodeSelectorOpIn || len(expr.Values) == 0 {
+				continue
+			}
+			if expr.Key == k8sNodeLabelKeyHostname {
+				return expr.Values[0]
+			}
+			if node == "" {
+				node = expr.Values[0]
+			}
+		}
+	}
+	return node
+}
+
+// metricsHandler serves the metrics of the metricsRegistry.
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHelperJobNode(t *testing.T) {
+	newJob := func(exprs ...corev1.NodeSelectorRequirement) *batchv1.Job {
+		hJob := &batchv1.Job{}
+		if len(exprs) > 0 {
+			hJob.Spec.Template.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: exprs}},
+					},
+				},
+			}
+		}
+		return hJob
+	}
+	testCases := map[string]struct {
+		hJob       *batchv1.Job
+		expectNode string
+	}{
+		"no affinity": {
+			hJob: newJob(),
+		},
+		"hostname": {
+			hJob: newJob(
+				corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+				corev1.NodeSelectorRequirement{Key: k8sNodeLabelKeyHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}},
+			),
+			expectNode: "node-1",
+		},
+		"custom node affinity label": {
+			hJob: newJob(
+				corev1.NodeSelectorRequirement{Key: "openebs.io/nodeid", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-id-1"}},
+			),
+			expectNode: "node-id-1",
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			if node := helperJobNode(v.hJob); node != v.expectNode {
+				t.Errorf("expected node %q, but got %q", v.expectNode, node)
+			}
+		})
+	}
+}
+
+func TestObserveHelperJob(t *testing.T) {
+	hJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "init-pvc-0001",
+			Labels: map[string]string{helperOperationLabel: "init"},
+		},
+	}
+	hJob.Spec.Template.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: k8sNodeLabelKeyHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"metrics-node"}},
+					},
+				}},
+			},
+		},
+	}
+
+	done := observeHelperJob(hJob)
+	if inFlight := testutil.ToFloat64(inFlightHelperPods.WithLabelValues("metrics-node")); inFlight != 1 {
+		t.Errorf("expected 1 helper pod in flight, but got %v", inFlight)
+	}
+	done()
+	if inFlight := testutil.ToFloat64(inFlightHelperPods.WithLabelValues("metrics-node")); inFlight != 0 {
+		t.Errorf("expected no helper pod in flight, but got %v", inFlight)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	countFailure(eventCodeProvisionFailure, "Volume initialization failed")
+
+	server := httptest.NewServer(metricsHandler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+
+	for _, expected := range []string{
+		`openebs_localpv_failures_total{event_code="local.pv.provision.failure",reason="Volume initialization failed"}`,
+		`openebs_localpv_leader 0`,
+	} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("expected metrics to contain %v, but got %v", expected, string(body))
+		}
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	analytics "github.com/openebs/google-analytics-4/usage"
 	"github.com/openebs/maya/pkg/alertlog"
@@ -42,7 +43,12 @@ import (
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
 )
@@ -59,7 +65,7 @@ const (
 // NewProvisioner will create a new Provisioner object and initialize
 //
 //	it with global information used across PV create and delete operations.
-func NewProvisioner(kubeClient *clientset.Clientset) (*Provisioner, error) {
+func NewProvisioner(kubeClient clientset.Interface, dynamicClient dynamic.Interface) (*Provisioner, error) {
 
 	namespace := getOpenEBSNamespace() //menv.Get(menv.OpenEBSNamespace)
 	if len(strings.TrimSpace(namespace)) == 0 {
@@ -67,9 +73,15 @@ func NewProvisioner(kubeClient *clientset.Clientset) (*Provisioner, error) {
 	}
 
 	p := &Provisioner{
-		kubeClient:  kubeClient,
-		namespace:   namespace,
-		helperImage: getDefaultHelperImage(),
+		kubeClient:         kubeClient,
+		dynamicClient:      dynamicClient,
+		ndm:                kubeNDMClient{},
+		namespace:          namespace,
+		helperNamespace:    getHelperNamespace(),
+		helperImage:        getDefaultHelperImage(),
+		basePathRoundRobin: newBasePathRoundRobin(),
+		operations:         newOperationTracker(),
+		quotaProjects:      newQuotaProjectAllocator(),
 		defaultConfig: []mconfig.Config{
 			{
 				Name:  KeyPVBasePath,
@@ -79,9 +91,31 @@ func NewProvisioner(kubeClient *clientset.Clientset) (*Provisioner, error) {
 	}
 	p.getVolumeConfig = p.GetVolumeConfig
 
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	p.recorder = eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: provisionerName})
+
 	return p, nil
 }
 
+// getHelperNamespace returns the namespace of the helper jobs, and of
+// the secrets used by the helper pods.
+func (p *Provisioner) getHelperNamespace() string {
+	if p.helperNamespace == "" {
+		return p.namespace
+	}
+	return p.helperNamespace
+}
+
+// recordEvent emits an event of the PV or PVC, if the Provisioner has
+// an event recorder and the object is set.
+func (p *Provisioner) recordEvent(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if p.recorder == nil || object == nil {
+		return
+	}
+	p.recorder.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
 // SupportsBlock will be used by controller to determine if block mode is
 //
 //	supported by the host path provisioner.
@@ -93,6 +127,12 @@ func (p *Provisioner) SupportsBlock(_ context.Context) bool {
 //
 //	to be provisioned and a valid PV spec returned.
 func (p *Provisioner) Provision(ctx context.Context, opts pvController.ProvisionOptions) (*v1.PersistentVolume, pvController.ProvisioningState, error) {
+	start := time.Now()
+	done, err := p.operations.track("provision "+opts.PVName, provisionStallTimeout)
+	if err != nil {
+		return nil, pvController.ProvisioningNoChange, err
+	}
+	defer done()
 	pvc := opts.PVC
 
 	// validate pvc dataSource
@@ -109,6 +149,16 @@ func (p *Provisioner) Provision(ctx context.Context, opts pvController.Provision
 		}
 	}
 
+	// A clone or a restored snapshot is created on the node of the
+	// data source.
+	if hasVolumeDataSource(pvc) {
+		node, state, err := p.getDataSourceNode(ctx, pvc, opts.SelectedNode)
+		if err != nil {
+			return nil, state, err
+		}
+		opts.SelectedNode = node
+	}
+
 	if opts.SelectedNode == nil {
 		return nil, pvController.ProvisioningReschedule, fmt.Errorf("configuration error, no node was specified")
 	}
@@ -129,6 +179,7 @@ func (p *Provisioner) Provision(ctx context.Context, opts pvController.Provision
 
 	//TODO: Determine if hostpath or device based Local PV should be created
 	stgType := pvCASConfig.GetStorageType()
+	defer observeDuration(provisionDuration, stgType, start)
 	size := resource.Quantity{}
 	reqMap := pvc.Spec.Resources.Requests
 	if reqMap != nil {
@@ -136,24 +187,55 @@ func (p *Provisioner) Provision(ctx context.Context, opts pvController.Provision
 	}
 	sendEventOrIgnore(pvc.Name, name, size.String(), stgType, analytics.VolumeProvision)
 
-	// todo: Disable the localpv device provisioning for now. Revisit later to remove the code path.
-
 	// EXCEPTION: Block VolumeMode
 	if *opts.PVC.Spec.VolumeMode == v1.PersistentVolumeBlock && stgType != "device" {
 		return nil, pvController.ProvisioningFinished, fmt.Errorf("PV with BlockMode is not supported with StorageType %v", stgType)
 	}
 
+	if hasVolumeDataSource(pvc) && stgType != "hostpath" {
+		return nil, pvController.ProvisioningFinished, fmt.Errorf("dataSource is not supported with StorageType %v", stgType)
+	}
+
+	// Reschedule the volume if the capacity published for the
+	// StorageClass on the selected node is not enough. A volume with a
+	// dataSource can only be created on the node of the dataSource, and
+	// an AbsolutePath is not under the measured BasePath.
+	if stgType == "hostpath" && isCapacityTrackingEnabled() &&
+		!hasVolumeDataSource(pvc) && !pvCASConfig.IsAbsolutePath() {
+		if state, err := p.checkNodeCapacity(ctx, opts.StorageClass.Name, opts.SelectedNode, size); err != nil {
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Not enough capacity on the selected node",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Not enough capacity on the selected node: %v", err)
+			countFailure(eventCodeProvisionFailure, "Not enough capacity on the selected node")
+			return nil, state, err
+		}
+	}
+
+	// StorageType: Device
+	if stgType == "device" {
+		return p.ProvisionBlockDevice(ctx, opts, pvCASConfig)
+	}
+
 	// StorageType: Hostpath
 	if stgType == "hostpath" {
-		return p.ProvisionHostPath(ctx, opts, pvCASConfig)
+		pv, state, err := p.ProvisionHostPath(ctx, opts, pvCASConfig)
+		p.recordHelperPodFailure(pvc, err)
+		return pv, state, err
 	}
 	alertlog.Logger.Errorw("",
-		"eventcode", "local.pv.provision.failure",
+		"eventcode", eventCodeProvisionFailure,
 		"msg", "Failed to provision Local PV",
 		"rname", opts.PVName,
 		"reason", "StorageType not supported",
 		"storagetype", stgType,
 	)
+	p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: StorageType %v not supported", stgType)
+	countFailure(eventCodeProvisionFailure, "StorageType not supported")
 	return nil, pvController.ProvisioningFinished, fmt.Errorf("PV with StorageType %v is not supported", stgType)
 }
 
@@ -164,12 +246,27 @@ func (p *Provisioner) Provision(ctx context.Context, opts pvController.Provision
 //	to delete the host path from the node.
 func (p *Provisioner) Delete(ctx context.Context, pv *v1.PersistentVolume) (err error) {
 	defer func() {
+		// The controller checks the type of an IgnoredError, so it
+		// is returned without wrapping.
+		if ignoredErr, ok := errors.Cause(err).(*pvController.IgnoredError); ok {
+			err = ignoredErr
+			return
+		}
 		err = errors.Wrapf(err, "failed to delete volume %v", pv.Name)
 	}()
 	//Initiate clean up only when reclaim policy is not retain.
 	if pv.Spec.PersistentVolumeReclaimPolicy != v1.PersistentVolumeReclaimRetain {
-		//TODO: Determine the type of PV
 		pvType := GetLocalPVType(pv)
+		stallTimeout := deleteStallTimeout
+		if getWipePolicy(pv) != "" {
+			stallTimeout = wipeStallTimeout
+		}
+		done, tErr := p.operations.track("delete "+pv.Name, stallTimeout)
+		if tErr != nil {
+			return tErr
+		}
+		defer done()
+		defer observeDuration(deleteDuration, strings.TrimPrefix(pvType, "local-"), time.Now())
 		size := resource.Quantity{}
 		reqMap := pv.Spec.Capacity
 		if reqMap != nil {
@@ -181,26 +278,44 @@ func (p *Provisioner) Delete(ctx context.Context, pv *v1.PersistentVolume) (err
 			pvcName = pv.Spec.ClaimRef.Name
 		}
 		sendEventOrIgnore(pvcName, pv.Name, size.String(), pvType, analytics.VolumeDeprovision)
-		// todo: Disable the localpv device deprovisioning for now. Revisit later to remove the code path.
+
+		if pvType == "local-device" {
+			err = p.DeleteBlockDevice(ctx, pv)
+			if err != nil {
+				alertlog.Logger.Errorw("",
+					"eventcode", eventCodeDeleteFailure,
+					"msg", "Failed to delete Local PV",
+					"rname", pv.Name,
+					"reason", "failed to release block device claim",
+					"storagetype", pvType,
+				)
+				p.recordEvent(pv, v1.EventTypeWarning, eventCodeDeleteFailure, "Failed to delete Local PV: failed to release block device claim: %v", err)
+				countFailure(eventCodeDeleteFailure, "failed to release block device claim")
+			}
+			return err
+		}
 
 		err = p.DeleteHostPath(ctx, pv)
 		if err != nil {
 			alertlog.Logger.Errorw("",
-				"eventcode", "local.pv.delete.failure",
+				"eventcode", eventCodeDeleteFailure,
 				"msg", "Failed to delete Local PV",
 				"rname", pv.Name,
 				"reason", "failed to delete host path",
 				"storagetype", pvType,
 			)
+			p.recordEvent(pv, v1.EventTypeWarning, eventCodeDeleteFailure, "Failed to delete Local PV: failed to delete host path: %v", err)
+			countFailure(eventCodeDeleteFailure, "failed to delete host path")
 		}
 		return err
 	}
 	klog.Infof("Retained volume %v", pv.Name)
 	alertlog.Logger.Infow("",
-		"eventcode", "local.pv.delete.success",
+		"eventcode", eventCodeDeleteSuccess,
 		"msg", "Successfully deleted Local PV",
 		"rname", pv.Name,
 	)
+	p.recordEvent(pv, v1.EventTypeNormal, eventCodeDeleteSuccess, "Successfully deleted Local PV")
 	return nil
 }
 
@@ -218,8 +333,8 @@ func sendEventOrIgnore(pvcName, pvName, capacity, stgType, method string) {
 }
 
 // validateVolumeSource validates datasource field of the pvc.
-// - clone - not handled by this provisioner
-// - snapshot - not handled by this provisioner
+// - clone - handled by this provisioner for hostpath volumes
+// - snapshot - handled by this provisioner for hostpath volumes
 // - volume populator - not handled by this provisioner
 func validateVolumeSource(pvc v1.PersistentVolumeClaim) error {
 	if pvc.Spec.DataSource != nil {
@@ -231,15 +346,18 @@ func validateVolumeSource(pvc v1.PersistentVolumeClaim) error {
 
 		// DataSource is snapshot
 		case SnapshotKind:
-			if *(pvc.Spec.DataSource.APIGroup) != SnapshotAPIGroup {
+			if pvc.Spec.DataSource.APIGroup == nil || *(pvc.Spec.DataSource.APIGroup) != SnapshotAPIGroup {
 				return fmt.Errorf("snapshot feature not supported by this provisioner")
 			}
-			return fmt.Errorf("datasource `%s` of group `%s` is not handled by the provisioner",
-				pvc.Spec.DataSource.Kind, *pvc.Spec.DataSource.APIGroup)
+			return nil
 
 		// DataSource is pvc
 		case PVCKind:
-			return fmt.Errorf("clone feature not supported by this provisioner")
+			if pvc.Spec.DataSource.APIGroup != nil && *pvc.Spec.DataSource.APIGroup != "" {
+				return fmt.Errorf("datasource `%s` of group `%s` is not handled by the provisioner",
+					pvc.Spec.DataSource.Kind, *pvc.Spec.DataSource.APIGroup)
+			}
+			return nil
 
 		// Custom DataSource (volume populator)
 		default:
@@ -0,0 +1,155 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// BasePathPolicyLeastUsed picks the BasePath whose filesystem has
+	// the least used bytes on the node.
+	BasePathPolicyLeastUsed = "LeastUsed"
+	// BasePathPolicyFewestVolumes picks the BasePath with the fewest
+	// hostpath volumes on the node.
+	BasePathPolicyFewestVolumes = "FewestVolumes"
+	// BasePathPolicyRoundRobin picks the BasePaths in turn for the
+	// volumes of a StorageClass on a node.
+	BasePathPolicyRoundRobin = "RoundRobin"
+)
+
+// basePathRoundRobin tracks the next BasePath to be picked for the
+// volumes of a StorageClass on a node. The state is kept in memory,
+// and starts again from the first BasePath when the provisioner
+// restarts.
+type basePathRoundRobin struct {
+	sync.Mutex
+	next map[string]int
+}
+
+func newBasePathRoundRobin() *basePathRoundRobin {
+	return &basePathRoundRobin{next: map[string]int{}}
+}
+
+// pick returns the index of the next of count BasePaths for the key.
+func (r *basePathRoundRobin) pick(key string, count int) int {
+	r.Lock()
+	defer r.Unlock()
+	index := r.next[key] % count
+	r.next[key] = index + 1
+	return index
+}
+
+// selectBasePath picks the BasePath of the volume out of the BasePaths
+// of the StorageClass, using the BasePathPolicy. An empty path is
+// returned if BasePaths is not set.
+func (p *Provisioner) selectBasePath(ctx context.Context, volumeConfig *VolumeConfig, node *v1.Node,
+	nodeAffinityLabels map[string]string, size resource.Quantity) (string, pvController.ProvisioningState, error) {
+	basePaths, err := volumeConfig.GetBasePaths()
+	if err != nil {
+		return "", pvController.ProvisioningFinished, err
+	}
+	if len(basePaths) == 0 {
+		return "", pvController.ProvisioningFinished, nil
+	}
+	policy, err := volumeConfig.GetBasePathPolicy()
+	if err != nil {
+		return "", pvController.ProvisioningFinished, err
+	}
+	if len(basePaths) == 1 {
+		return basePaths[0], pvController.ProvisioningFinished, nil
+	}
+
+	switch policy {
+	case BasePathPolicyRoundRobin:
+		key := volumeConfig.scName + "/" + GetNodeHostname(node)
+		return basePaths[p.basePathRoundRobin.pick(key, len(basePaths))], pvController.ProvisioningFinished, nil
+	case BasePathPolicyLeastUsed:
+		return p.selectLeastUsedBasePath(ctx, volumeConfig.pvName, basePaths, node, size)
+	default:
+		basePath, err := p.selectFewestVolumesBasePath(ctx, basePaths, nodeAffinityLabels)
+		return basePath, pvController.ProvisioningFinished, err
+	}
+}
+
+// selectFewestVolumesBasePath returns the BasePath with the fewest
+// hostpath volumes on the node with the given affinity labels. The
+// first of the BasePaths is returned on a tie.
+func (p *Provisioner) selectFewestVolumesBasePath(ctx context.Context, basePaths []string, nodeAffinityLabels map[string]string) (string, error) {
+	pvList, err := p.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
+		LabelSelector: string(mconfig.CASTypeKey) + "=local-hostpath",
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list persistentvolumes")
+	}
+
+	volumes := map[string]int{}
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		if !reflect.DeepEqual(persistentvolume.NewForAPIObject(pv).GetAffinitedNodeLabels(), nodeAffinityLabels) {
+			continue
+		}
+		volumes[pv.Annotations[rootPathAnnotation]]++
+	}
+
+	selected := basePaths[0]
+	for _, basePath := range basePaths[1:] {
+		if volumes[basePath] < volumes[selected] {
+			selected = basePath
+		}
+	}
+	return selected, nil
+}
+
+// selectLeastUsedBasePath returns the BasePath whose filesystem has the
+// least used bytes on the node, out of the BasePaths with at least size
+// bytes available. The first of the BasePaths is returned on a tie.
+func (p *Provisioner) selectLeastUsedBasePath(ctx context.Context, pvName string, basePaths []string,
+	node *v1.Node, size resource.Quantity) (string, pvController.ProvisioningState, error) {
+	selected, selectedUsed := "", int64(0)
+	for i, basePath := range basePaths {
+		used, available, err := p.getFilesystemUsage(ctx, node, fmt.Sprintf("%v-%d", pvName, i), basePath)
+		if err != nil {
+			return "", pvController.ProvisioningFinished, errors.Wrapf(err, "failed to get usage of base path %v", basePath)
+		}
+		klog.V(4).Infof("Base path %v on node %v has %v bytes used and %v bytes available", basePath, node.Name, used, available)
+		if available < size.Value() {
+			continue
+		}
+		if selected == "" || used < selectedUsed {
+			selected, selectedUsed = basePath, used
+		}
+	}
+	if selected == "" {
+		return "", pvController.ProvisioningReschedule,
+			errors.Errorf("none of the base paths %v on node %v has %v available", basePaths, node.Name, size.String())
+	}
+	return selected, pvController.ProvisioningFinished, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func TestSelectBasePath(t *testing.T) {
+	fakeConfig := func(policy string, basePaths ...string) *VolumeConfig {
+		c := &VolumeConfig{
+			pvName:     "pvName",
+			scName:     "scName",
+			options:    map[string]interface{}{KeyPVBasePath: map[string]string{"value": "/var/openebs/local"}},
+			configList: map[string]interface{}{},
+		}
+		if policy != "" {
+			c.options[KeyBasePathPolicy] = map[string]string{"value": policy}
+		}
+		if basePaths != nil {
+			c.configList[KeyPVBasePaths] = basePaths
+		}
+		return c
+	}
+	fakePV := func(name, node, rootPath string) runtime.Object {
+		pv, err := persistentvolume.NewBuilder().
+			WithName(name).
+			WithLabels(map[string]string{string(mconfig.CASTypeKey): "local-hostpath"}).
+			WithAnnotations(map[string]string{rootPathAnnotation: rootPath}).
+			WithCapacityQty(resource.MustParse("1Gi")).
+			WithLocalHostDirectory(rootPath + "/" + name).
+			WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: node}).
+			Build()
+		if err != nil {
+			t.Fatalf("failed to build pv: %v", err)
+		}
+		return pv
+	}
+
+	testCases := map[string]struct {
+		config      *VolumeConfig
+		pvs         []runtime.Object
+		expectPaths []string
+		expectError bool
+	}{
+		"base paths not set": {
+			config:      fakeConfig(""),
+			expectPaths: []string{""},
+		},
+		"single base path": {
+			config:      fakeConfig(BasePathPolicyLeastUsed, "/mnt/disk1/"),
+			expectPaths: []string{"/mnt/disk1"},
+		},
+		"duplicate base paths": {
+			config:      fakeConfig(BasePathPolicyLeastUsed, "/mnt/disk1", "/mnt/disk1/"),
+			expectPaths: []string{"/mnt/disk1"},
+		},
+		"relative base path": {
+			config:      fakeConfig("", "/mnt/disk1", "mnt/disk2"),
+			expectError: true,
+		},
+		"root base path": {
+			config:      fakeConfig("", "/mnt/disk1", "/"),
+			expectError: true,
+		},
+		"invalid policy": {
+			config:      fakeConfig("MostUsed", "/mnt/disk1", "/mnt/disk2"),
+			expectError: true,
+		},
+		"fewest volumes": {
+			config: fakeConfig(BasePathPolicyFewestVolumes, "/mnt/disk1", "/mnt/disk2", "/mnt/disk3"),
+			pvs: []runtime.Object{
+				fakePV("pv1", "node-1", "/mnt/disk1"),
+				fakePV("pv2", "node-1", "/mnt/disk2"),
+				fakePV("pv3", "node-1", "/mnt/disk2"),
+				fakePV("pv4", "node-2", "/mnt/disk3"),
+				fakePV("pv5", "node-2", "/mnt/disk3"),
+			},
+			expectPaths: []string{"/mnt/disk3"},
+		},
+		"fewest volumes on a tie": {
+			config: fakeConfig("", "/mnt/disk1", "/mnt/disk2"),
+			pvs: []runtime.Object{
+				fakePV("pv1", "node-1", "/mnt/disk1"),
+				fakePV("pv2", "node-1", "/mnt/disk2"),
+			},
+			expectPaths: []string{"/mnt/disk1"},
+		},
+		"round robin": {
+			config:      fakeConfig(BasePathPolicyRoundRobin, "/mnt/disk1", "/mnt/disk2"),
+			expectPaths: []string{"/mnt/disk1", "/mnt/disk2", "/mnt/disk1"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p := &Provisioner{
+				kubeClient:         fake.NewSimpleClientset(tc.pvs...),
+				basePathRoundRobin: newBasePathRoundRobin(),
+			}
+			node := fakeNode("node-1")
+			nodeAffinityLabels := map[string]string{k8sNodeLabelKeyHostname: "node-1"}
+
+			for _, expectPath := range tc.expectPaths {
+				basePath, _, err := p.selectBasePath(context.TODO(), tc.config, node, nodeAffinityLabels, resource.MustParse("1Gi"))
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				if basePath != expectPath {
+					t.Errorf("expected base path %q, got %q", expectPath, basePath)
+				}
+			}
+			if tc.expectError {
+				if _, _, err := p.selectBasePath(context.TODO(), tc.config, node, nodeAffinityLabels, resource.MustParse("1Gi")); err == nil {
+					t.Errorf("expected error, got none")
+				}
+			}
+		})
+	}
+}
+
+func TestSetBasePath(t *testing.T) {
+	c := &VolumeConfig{
+		pvName:  "pvName",
+		options: map[string]interface{}{KeyPVBasePath: map[string]string{"value": "/var/openebs/local"}},
+	}
+	c.setBasePath("/mnt/disk2")
+
+	path, err := c.GetPath()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if path != "/mnt/disk2/pvName" {
+		t.Errorf("expected path %q, got %q", "/mnt/disk2/pvName", path)
+	}
+	rootPath, _ := c.GetRootPath()
+	if rootPath != "/mnt/disk2" {
+		t.Errorf("expected root path %q, got %q", "/mnt/disk2", rootPath)
+	}
+}
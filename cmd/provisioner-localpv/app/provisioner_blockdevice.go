@@ -59,6 +59,14 @@ func (p *Provisioner) ProvisionBlockDevice(ctx context.Context, opts pvControlle
 		return nil, pvController.ProvisioningFinished, errors.Errorf("Cannot use deprecated \"NodeAffinityLabel\" config option")
 	}
 
+	wipePolicy, err := volumeConfig.GetWipePolicy()
+	if err == nil {
+		err = validateWipePolicy(wipePolicy, stgType, *opts.PVC.Spec.VolumeMode)
+	}
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+
 	// nodeAffinityLabels contains all the custom node affinity labels.
 	// This helps in cases where the hostname changes when the node is removed and
 	// added back.
@@ -87,12 +95,14 @@ func (p *Provisioner) ProvisionBlockDevice(ctx context.Context, opts pvControlle
 	if err != nil {
 		klog.Infof("Initialize volume %v failed: %v", name, err)
 		alertlog.Logger.Errorw("",
-			"eventcode", "local.pv.provision.failure",
+			"eventcode", eventCodeProvisionFailure,
 			"msg", "Failed to provision Local PV",
 			"rname", opts.PVName,
 			"reason", "Block device initialization failed",
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Block device initialization failed: %v", err)
+		countFailure(eventCodeProvisionFailure, "Block device initialization failed")
 		return nil, pvController.ProvisioningFinished, err
 	}
 	klog.Infof("Creating volume %v on %v at %v(%v)", name, nodeHostname, path, blkPath)
@@ -111,6 +121,9 @@ func (p *Provisioner) ProvisionBlockDevice(ctx context.Context, opts pvControlle
 	// Use annotations to specify the context using which the PV was created.
 	volAnnotations := make(map[string]string)
 	volAnnotations[bdcStorageClassAnnotation] = blkDevOpts.bdcName
+	if wipePolicy != WipePolicyNone {
+		volAnnotations[wipePolicyAnnotation] = wipePolicy
+	}
 	//fstype := casVolume.Spec.FSType
 
 	labels := make(map[string]string)
@@ -138,20 +151,23 @@ func (p *Provisioner) ProvisionBlockDevice(ctx context.Context, opts pvControlle
 
 	if err != nil {
 		alertlog.Logger.Errorw("",
-			"eventcode", "local.pv.provision.failure",
+			"eventcode", eventCodeProvisionFailure,
 			"msg", "Failed to provision Local PV",
 			"rname", opts.PVName,
 			"reason", "Building volume failed",
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Building volume failed: %v", err)
+		countFailure(eventCodeProvisionFailure, "Building volume failed")
 		return nil, pvController.ProvisioningFinished, err
 	}
 	alertlog.Logger.Infow("",
-		"eventcode", "local.pv.provision.success",
+		"eventcode", eventCodeProvisionSuccess,
 		"msg", "Successfully provisioned Local PV",
 		"rname", opts.PVName,
 		"storagetype", stgType,
 	)
+	p.recordEvent(pvc, v1.EventTypeNormal, eventCodeProvisionSuccess, "Successfully provisioned Local PV")
 	return pvObj, pvController.ProvisioningFinished, nil
 }
 
@@ -171,6 +187,14 @@ func (p *Provisioner) DeleteBlockDevice(ctx context.Context, pv *v1.PersistentVo
 	//Determine if a BDC is set on the PV and save it to BlockDeviceOptions
 	blkDevOpts.setBlockDeviceClaimFromPV(pv)
 
+	//Wipe the block device before it is released, as it can be claimed
+	//by another volume once the BDC is deleted.
+	if wipePolicy := getWipePolicy(pv); wipePolicy != "" && blkDevOpts.hasBDC() {
+		if err := p.wipeBlockDevice(ctx, pv, wipePolicy); err != nil {
+			return err
+		}
+	}
+
 	//Initiate clean up only when reclaim policy is not retain.
 	//TODO: this part of the code could be eliminated by setting up
 	// BDC owner reference to PVC.
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// sourceModeCopy denotes that the data source directory holds
+	// a copy of the data.
+	sourceModeCopy = "copy"
+	// sourceModeTar denotes that the data source directory holds
+	// the data in a tarball named snapshot.tar.
+	sourceModeTar = "tar"
+)
+
+// volumeDataSource is the clone source volume or the snapshot from
+// which a hostpath volume is populated.
+type volumeDataSource struct {
+	// name is the name of the source PV or VolumeSnapshotContent
+	name string
+	// path is the directory of the data source on the node
+	path string
+	// mode is either sourceModeCopy or sourceModeTar
+	mode string
+	// nodeAffinityLabels are the labels of the node of the data source
+	nodeAffinityLabels map[string]string
+	// size is the minimum size of a volume populated from the data source
+	size resource.Quantity
+}
+
+// hasVolumeDataSource returns true if the PVC should be populated from
+// a clone source volume or a snapshot.
+func hasVolumeDataSource(pvc *v1.PersistentVolumeClaim) bool {
+	return isCloneRequest(pvc) || isSnapshotRestoreRequest(pvc)
+}
+
+// isCloneRequest returns true if the PVC requests a clone of another PVC.
+func isCloneRequest(pvc *v1.PersistentVolumeClaim) bool {
+	return pvc.Spec.DataSource != nil && pvc.Spec.DataSource.Kind == PVCKind
+}
+
+// isSnapshotRestoreRequest returns true if the PVC requests a restore
+// of a VolumeSnapshot.
+func isSnapshotRestoreRequest(pvc *v1.PersistentVolumeClaim) bool {
+	return pvc.Spec.DataSource != nil && pvc.Spec.DataSource.Kind == SnapshotKind &&
+		pvc.Spec.DataSource.APIGroup != nil && *pvc.Spec.DataSource.APIGroup == SnapshotAPIGroup
+}
+
+// getVolumeDataSource returns the data source of the PVC. The volume
+// should not be smaller than the data source.
+func (p *Provisioner) getVolumeDataSource(ctx context.Context, pvc *v1.PersistentVolumeClaim) (*volumeDataSource, error) {
+	var source *volumeDataSource
+	var err error
+	if isSnapshotRestoreRequest(pvc) {
+		source, err = p.getSnapshotDataSource(ctx, pvc)
+	} else {
+		source, err = p.getCloneDataSource(ctx, pvc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	size := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	if size.Cmp(source.size) < 0 {
+		return nil, errors.Errorf("requested size %v is smaller than the size %v of the data source %v",
+			size.String(), source.size.String(), source.name)
+	}
+	return source, nil
+}
+
+// getCloneDataSource returns the hostpath PV bound to the source PVC
+// of a clone request. The source PVC should be in the namespace of the
+// clone.
+func (p *Provisioner) getCloneDataSource(ctx context.Context, pvc *v1.PersistentVolumeClaim) (*volumeDataSource, error) {
+	srcName := pvc.Spec.DataSource.Name
+	srcPVC, err := p.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(ctx, srcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get clone source pvc %v/%v", pvc.Namespace, srcName)
+	}
+	if srcPVC.DeletionTimestamp != nil {
+		return nil, errors.Errorf("clone source pvc %v/%v is being deleted", pvc.Namespace, srcName)
+	}
+
+	srcPV, err := p.getBoundHostPathVolume(ctx, srcPVC)
+	if err != nil {
+		return nil, err
+	}
+
+	pvObj := persistentvolume.NewForAPIObject(srcPV)
+	return &volumeDataSource{
+		name:               srcPV.Name,
+		path:               pvObj.GetPath(),
+		mode:               sourceModeCopy,
+		nodeAffinityLabels: pvObj.GetAffinitedNodeLabels(),
+		size:               srcPV.Spec.Capacity[v1.ResourceStorage],
+	}, nil
+}
+
+// getBoundHostPathVolume returns the hostpath Local PV bound to the PVC.
+func (p *Provisioner) getBoundHostPathVolume(ctx context.Context, pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolume, error) {
+	if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil, errors.Errorf("pvc %v/%v is not bound", pvc.Namespace, pvc.Name)
+	}
+
+	pv, err := p.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get volume %v", pvc.Spec.VolumeName)
+	}
+	if pv.Annotations[annDynamicallyProvisioned] != provisionerName ||
+		GetLocalPVType(pv) != "local-hostpath" {
+		return nil, errors.Errorf("volume %v is not a hostpath Local PV", pv.Name)
+	}
+	return pv, nil
+}
+
+// getDataSourceNode returns the node of the data source. The volume is
+// populated from the data source directory on the node and hence must be
+// created on the same node. If the scheduler has selected another node,
+// the provisioning is rescheduled.
+func (p *Provisioner) getDataSourceNode(ctx context.Context, pvc *v1.PersistentVolumeClaim, selectedNode *v1.Node) (*v1.Node, pvController.ProvisioningState, error) {
+	source, err := p.getVolumeDataSource(ctx, pvc)
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+
+	if len(source.nodeAffinityLabels) == 0 {
+		return nil, pvController.ProvisioningFinished, errors.Errorf("cannot find affinited node details of data source %v", source.name)
+	}
+
+	if selectedNode == nil {
+		node, err := p.GetNodeObjectFromLabels(source.nodeAffinityLabels)
+		if err != nil {
+			return nil, pvController.ProvisioningFinished, err
+		}
+		return node, pvController.ProvisioningFinished, nil
+	}
+
+	for key, value := range source.nodeAffinityLabels {
+		if GetNodeLabelValue(selectedNode, key) != value {
+			return nil, pvController.ProvisioningReschedule, errors.Errorf(
+				"volume must be created on the node with labels {%v} of the data source %v, selected node %v does not match",
+				source.nodeAffinityLabels, source.name, selectedNode.Name)
+		}
+	}
+	return selectedNode, pvController.ProvisioningFinished, nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func fakeCloneSource(t *testing.T, casType, size string) (*v1.PersistentVolumeClaim, *v1.PersistentVolume) {
+	pv, err := persistentvolume.NewBuilder().
+		WithName("source-pv").
+		WithLabels(map[string]string{string(mconfig.CASTypeKey): casType}).
+		WithAnnotations(map[string]string{annDynamicallyProvisioned: provisionerName}).
+		WithCapacityQty(resource.MustParse(size)).
+		WithLocalHostDirectory("/var/openebs/local/source-pv").
+		WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: "node-1"}).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build source pv: %v", err)
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: "default"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: pv.Name},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+	return pvc, pv
+}
+
+func fakeNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{k8sNodeLabelKeyHostname: name},
+		},
+	}
+}
+
+func TestGetDataSourceNode(t *testing.T) {
+	clonePVC := func(size string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "clone", Namespace: "default"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				DataSource: &v1.TypedLocalObjectReference{Kind: PVCKind, Name: "source"},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		casType      string
+		cloneSize    string
+		selectedNode *v1.Node
+		expectNode   string
+		expectState  pvController.ProvisioningState
+		expectErr    bool
+	}{
+		"no selected node": {
+			casType:     "local-hostpath",
+			cloneSize:   "1Gi",
+			expectNode:  "node-1",
+			expectState: pvController.ProvisioningFinished,
+		},
+		"selected node of source": {
+			casType:      "local-hostpath",
+			cloneSize:    "2Gi",
+			selectedNode: fakeNode("node-1"),
+			expectNode:   "node-1",
+			expectState:  pvController.ProvisioningFinished,
+		},
+		"selected node other than source": {
+			casType:      "local-hostpath",
+			cloneSize:    "1Gi",
+			selectedNode: fakeNode("node-2"),
+			expectState:  pvController.ProvisioningReschedule,
+			expectErr:    true,
+		},
+		"clone smaller than source": {
+			casType:     "local-hostpath",
+			cloneSize:   "512Mi",
+			expectState: pvController.ProvisioningFinished,
+			expectErr:   true,
+		},
+		"source is not hostpath": {
+			casType:     "local-device",
+			cloneSize:   "1Gi",
+			expectState: pvController.ProvisioningFinished,
+			expectErr:   true,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			srcPVC, srcPV := fakeCloneSource(t, v.casType, "1Gi")
+			p := &Provisioner{kubeClient: fake.NewSimpleClientset(srcPVC, srcPV, fakeNode("node-1"), fakeNode("node-2"))}
+
+			node, state, err := p.getDataSourceNode(context.TODO(), clonePVC(v.cloneSize), v.selectedNode)
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %t got %v", v.expectErr, err)
+			}
+			if state != v.expectState {
+				t.Errorf("expected state %v got %v", v.expectState, state)
+			}
+			if !v.expectErr && node.Name != v.expectNode {
+				t.Errorf("expected node %v got %v", v.expectNode, node.Name)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotDataSource(t *testing.T) {
+	snapshotAPIGroup := SnapshotAPIGroup
+	restorePVC := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+		Spec: v1.PersistentVolumeClaimSpec{
+			DataSource: &v1.TypedLocalObjectReference{APIGroup: &snapshotAPIGroup, Kind: SnapshotKind, Name: "snap"},
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	fakeSnapshot := func(ready bool) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "snapshot.storage.k8s.io/v1",
+			"kind":       "VolumeSnapshot",
+			"metadata":   map[string]interface{}{"name": "snap", "namespace": "default"},
+			"status": map[string]interface{}{
+				"boundVolumeSnapshotContentName": "snapcontent-1",
+				"readyToUse":                     ready,
+			},
+		}}
+	}
+	fakeContent := func(driver string, restoreSize int64) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "snapshot.storage.k8s.io/v1",
+			"kind":       "VolumeSnapshotContent",
+			"metadata": map[string]interface{}{
+				"name": "snapcontent-1",
+				"annotations": map[string]interface{}{
+					snapshotPathAnnotation: "/var/openebs/local/.snapshots/snapcontent-1",
+					snapshotModeAnnotation: sourceModeTar,
+					snapshotNodeAnnotation: `{"kubernetes.io/hostname":"node-1"}`,
+				},
+			},
+			"spec":   map[string]interface{}{"driver": driver},
+			"status": map[string]interface{}{"restoreSize": restoreSize},
+		}}
+	}
+
+	testCases := map[string]struct {
+		snapshot   *unstructured.Unstructured
+		content    *unstructured.Unstructured
+		expectMode string
+		expectErr  bool
+	}{
+		"snapshot ready": {
+			snapshot:   fakeSnapshot(true),
+			content:    fakeContent(provisionerName, 1<<30),
+			expectMode: sourceModeTar,
+		},
+		"snapshot not ready": {
+			snapshot:  fakeSnapshot(false),
+			content:   fakeContent(provisionerName, 1<<30),
+			expectErr: true,
+		},
+		"snapshot of another driver": {
+			snapshot:  fakeSnapshot(true),
+			content:   fakeContent("example.io/other", 1<<30),
+			expectErr: true,
+		},
+		"restore smaller than snapshot": {
+			snapshot:  fakeSnapshot(true),
+			content:   fakeContent(provisionerName, 2<<30),
+			expectErr: true,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			listKinds := map[schema.GroupVersionResource]string{
+				volumeSnapshotGVR:        "VolumeSnapshotList",
+				volumeSnapshotContentGVR: "VolumeSnapshotContentList",
+			}
+			p := &Provisioner{
+				dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, v.snapshot, v.content),
+			}
+
+			source, err := p.getVolumeDataSource(context.TODO(), restorePVC)
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %t got %v", v.expectErr, err)
+			}
+			if v.expectErr {
+				return
+			}
+			if source.mode != v.expectMode {
+				t.Errorf("expected mode %v got %v", v.expectMode, source.mode)
+			}
+			if source.nodeAffinityLabels[k8sNodeLabelKeyHostname] != "node-1" {
+				t.Errorf("expected node node-1 got %v", source.nodeAffinityLabels)
+			}
+		})
+	}
+}
@@ -18,11 +18,15 @@ package app
 
 import (
 	"context"
+	"path/filepath"
+	"reflect"
+	"strconv"
 
 	"github.com/openebs/maya/pkg/alertlog"
 	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
@@ -36,6 +40,22 @@ const (
 	EnableExt4Quota string = "enableExt4Quota"
 	SoftLimitGrace  string = "softLimitGrace"
 	HardLimitGrace  string = "hardLimitGrace"
+
+	// rootPathAnnotation is set on the hostpath PV with the directory
+	// under which the volume directory was created.
+	rootPathAnnotation = "local.openebs.io/root-path"
+	// absolutePathAnnotation is set on the hostpath PV if the volume
+	// directory is a pre-existing AbsolutePath.
+	absolutePathAnnotation = "local.openebs.io/absolute-path"
+	// The directoryXXXAnnotations are set on the hostpath PV with the
+	// mode, owner and default ACL applied to the volume directory.
+	directoryModeAnnotation       = "local.openebs.io/directory-mode"
+	directoryUIDAnnotation        = "local.openebs.io/directory-uid"
+	directoryGIDAnnotation        = "local.openebs.io/directory-gid"
+	directoryDefaultACLAnnotation = "local.openebs.io/directory-default-acl"
+	// selinuxContextAnnotation is set on the hostpath PV with the
+	// SELinux context applied to the volume directory.
+	selinuxContextAnnotation = "local.openebs.io/selinux-context"
 )
 
 // ProvisionHostPath is invoked by the Provisioner which expect HostPath PV
@@ -60,46 +80,202 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 		}
 	}
 
+	// Resolve the BasePath on the selected node, and pick the BasePath
+	// of the volume if the StorageClass has more than one. The picked
+	// path is recorded as the root path of the PV.
+	if !volumeConfig.IsAbsolutePath() {
+		if err := volumeConfig.resolveBasePaths(opts.SelectedNode); err != nil {
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Unable to resolve base path",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Unable to resolve base path: %v", err)
+			countFailure(eventCodeProvisionFailure, "Unable to resolve base path")
+			return nil, pvController.ProvisioningFinished, err
+		}
+		basePath, state, err := p.selectBasePath(ctx, volumeConfig, opts.SelectedNode,
+			nodeAffinityLabels, pvc.Spec.Resources.Requests[v1.ResourceStorage])
+		if err != nil {
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Unable to select base path",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Unable to select base path: %v", err)
+			countFailure(eventCodeProvisionFailure, "Unable to select base path")
+			return nil, state, err
+		}
+		if basePath != "" {
+			volumeConfig.setBasePath(basePath)
+		}
+	}
+
 	path, err := volumeConfig.GetPath()
 	if err != nil {
 		alertlog.Logger.Errorw("",
-			"eventcode", "local.pv.provision.failure",
+			"eventcode", eventCodeProvisionFailure,
 			"msg", "Failed to provision Local PV",
 			"rname", opts.PVName,
 			"reason", "Unable to get volume config",
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Unable to get volume config: %v", err)
+		countFailure(eventCodeProvisionFailure, "Unable to get volume config")
+		return nil, pvController.ProvisioningFinished, err
+	}
+
+	rootPath, err := volumeConfig.GetRootPath()
+	if err != nil {
 		return nil, pvController.ProvisioningFinished, err
 	}
 
+	isAbsolutePath := volumeConfig.IsAbsolutePath()
+	// A RelativePath may be the same as, or nested in, the path of
+	// another volume, which would then be removed along with it. An
+	// AbsolutePath is a pre-existing directory that may be shared.
+	if !isAbsolutePath {
+		if err := p.checkPathInUse(ctx, path, nodeAffinityLabels); err != nil {
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Volume path in use",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Volume path in use: %v", err)
+			countFailure(eventCodeProvisionFailure, "Volume path in use")
+			return nil, pvController.ProvisioningFinished, err
+		}
+	}
+	if isAbsolutePath && (volumeConfig.IsXfsQuotaEnabled() || volumeConfig.IsExt4QuotaEnabled()) {
+		return nil, pvController.ProvisioningFinished, errors.Errorf("quota is not supported for volumes with AbsolutePath")
+	}
+	if isAbsolutePath && hasVolumeDataSource(pvc) {
+		return nil, pvController.ProvisioningFinished, errors.Errorf("dataSource is not supported for volumes with AbsolutePath")
+	}
+
+	dirMode, err := volumeConfig.GetDirectoryMode()
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	dirUID, dirGID, err := volumeConfig.GetDirectoryOwner()
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	dirDefaultACL, err := volumeConfig.GetDirectoryDefaultACL()
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	selinuxContext, err := volumeConfig.GetSELinuxContext()
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	seed, err := volumeConfig.GetSeed()
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	if seed != nil && (isAbsolutePath || hasVolumeDataSource(pvc)) {
+		return nil, pvController.ProvisioningFinished, errors.Errorf("seed is not supported for volumes with AbsolutePath or dataSource")
+	}
+	wipePolicy, err := volumeConfig.GetWipePolicy()
+	if err == nil {
+		err = validateWipePolicy(wipePolicy, stgType, v1.PersistentVolumeFilesystem)
+	}
+	if err != nil {
+		return nil, pvController.ProvisioningFinished, err
+	}
+	if isAbsolutePath && wipePolicy != WipePolicyNone {
+		return nil, pvController.ProvisioningFinished, errors.Errorf("wipe policy is not supported for volumes with AbsolutePath")
+	}
+
 	imagePullSecrets := GetImagePullSecrets(getOpenEBSImagePullSecrets())
 
 	klog.Infof("Creating volume %v at node with labels {%v}, path:%v,ImagePullSecrets:%v", name, nodeAffinityLabels, path, imagePullSecrets)
 
 	//Before using the path for local PV, make sure it is created.
-	initCmdsForPath := []string{"mkdir", "-m", "0777", "-p"}
+	//An AbsolutePath is expected to be already present on the node.
+	initCmdsForPath := []string{"mkdir", "-m", dirMode, "-p"}
 	podOpts := &HelperPodOptions{
 		cmdsForPath:        initCmdsForPath,
 		name:               name,
+		pvName:             name,
+		pvcName:            opts.PVC.Name,
+		pvcNamespace:       opts.PVC.Namespace,
+		eventObject:        pvc,
 		path:               path,
+		rootPath:           rootPath,
 		nodeAffinityLabels: nodeAffinityLabels,
 		serviceAccountName: saName,
 		selectedNodeTaints: taints,
 		imagePullSecrets:   imagePullSecrets,
+		helperImage:        volumeConfig.GetHelperImage(),
+		podTemplate:        volumeConfig.GetHelperPodTemplate(),
+		dirUID:             dirUID,
+		dirGID:             dirGID,
+		dirDefaultACL:      dirDefaultACL,
+		selinuxContext:     selinuxContext,
+	}
+	if seed != nil && seed.kind == KeySeedTarball {
+		podOpts.seedTarball = seed.name
+	} else if seed != nil {
+		seedSecret, err := p.createSeedSecret(ctx, name, pvc, seed)
+		if err != nil {
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Unable to get seed",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Unable to get seed: %v", err)
+			countFailure(eventCodeProvisionFailure, "Unable to get seed")
+			return nil, pvController.ProvisioningFinished, err
+		}
+		defer p.deleteSeedSecret(ctx, name)
+		podOpts.seedSecret = seedSecret.Name
+	}
+	// The mode is applied again in case the directory already exists.
+	if volumeConfig.getValue(KeyDirectoryMode) != "" {
+		podOpts.dirMode = dirMode
+	}
+	// The mode, owner and ACL of an AbsolutePath are not changed, as
+	// the directory may be shared with other volumes.
+	if isAbsolutePath {
+		podOpts.cmdsForPath = []string{"test", "-d"}
+		podOpts.dirMode, podOpts.dirUID, podOpts.dirGID, podOpts.dirDefaultACL, podOpts.selinuxContext = "", "", "", "", ""
 	}
 	iErr := p.createInitPod(ctx, podOpts)
 	if iErr != nil {
 		klog.Infof("Initialize volume %v failed: %v", name, iErr)
 		alertlog.Logger.Errorw("",
-			"eventcode", "local.pv.provision.failure",
+			"eventcode", eventCodeProvisionFailure,
 			"msg", "Failed to provision Local PV",
 			"rname", opts.PVName,
 			"reason", "Volume initialization failed",
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Volume initialization failed: %v", iErr)
+		countFailure(eventCodeProvisionFailure, "Volume initialization failed")
 		return nil, pvController.ProvisioningFinished, iErr
 	}
+	alertlog.Logger.Infow("",
+		"eventcode", eventCodeInitSuccess,
+		"msg", "Successfully initialized Local PV",
+		"rname", opts.PVName,
+		"storagetype", stgType,
+	)
+	p.recordEvent(pvc, v1.EventTypeNormal, eventCodeInitSuccess, "Successfully initialized Local PV")
 
+	// quotaProjectID is the quota project ID allocated to the volume
+	// directory, if quota is enabled, and quotaSoftLimitGrace and
+	// quotaHardLimitGrace are the limit grace applied with it.
+	var quotaProjectID uint32
+	var quotaSoftLimitGrace, quotaHardLimitGrace string
 	if volumeConfig.IsXfsQuotaEnabled() {
 		softLimitGrace := volumeConfig.getDataField(KeyXFSQuota, KeyQuotaSoftLimit)
 		hardLimitGrace := volumeConfig.getDataField(KeyXFSQuota, KeyQuotaHardLimit)
@@ -107,33 +283,44 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 
 		podOpts := &HelperPodOptions{
 			name:               name,
+			pvName:             name,
+			pvcName:            opts.PVC.Name,
+			pvcNamespace:       opts.PVC.Namespace,
+			eventObject:        pvc,
 			path:               path,
 			nodeAffinityLabels: nodeAffinityLabels,
 			serviceAccountName: saName,
 			selectedNodeTaints: taints,
 			imagePullSecrets:   imagePullSecrets,
+			helperImage:        volumeConfig.GetHelperImage(),
+			podTemplate:        volumeConfig.GetHelperPodTemplate(),
 			softLimitGrace:     softLimitGrace,
 			hardLimitGrace:     hardLimitGrace,
 			pvcStorage:         pvcStorage,
 		}
-		iErr := p.createQuotaPod(ctx, podOpts)
+		projectID, iErr := p.applyQuota(ctx, podOpts)
 		if iErr != nil {
 			klog.Infof("Applying quota failed: %v", iErr)
 			alertlog.Logger.Errorw("",
-				"eventcode", "local.pv.provision.failure",
+				"eventcode", eventCodeProvisionFailure,
 				"msg", "Failed to provision Local PV",
 				"rname", opts.PVName,
 				"reason", "Quota enforcement failed",
 				"storagetype", stgType,
 			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Quota enforcement failed: %v", iErr)
+			countFailure(eventCodeProvisionFailure, "Quota enforcement failed")
 			return nil, pvController.ProvisioningFinished, iErr
 		}
 		alertlog.Logger.Infow("",
-			"eventcode", "local.pv.quota.success",
+			"eventcode", eventCodeQuotaSuccess,
 			"msg", "Successfully applied quota",
 			"rname", opts.PVName,
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeNormal, eventCodeQuotaSuccess, "Successfully applied quota")
+		quotaProjectID = projectID
+		quotaSoftLimitGrace, quotaHardLimitGrace = softLimitGrace, hardLimitGrace
 	}
 
 	if volumeConfig.IsExt4QuotaEnabled() {
@@ -143,33 +330,99 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 
 		podOpts := &HelperPodOptions{
 			name:               name,
+			pvName:             name,
+			pvcName:            opts.PVC.Name,
+			pvcNamespace:       opts.PVC.Namespace,
+			eventObject:        pvc,
 			path:               path,
 			nodeAffinityLabels: nodeAffinityLabels,
 			serviceAccountName: saName,
 			selectedNodeTaints: taints,
 			imagePullSecrets:   imagePullSecrets,
+			helperImage:        volumeConfig.GetHelperImage(),
+			podTemplate:        volumeConfig.GetHelperPodTemplate(),
 			softLimitGrace:     softLimitGrace,
 			hardLimitGrace:     hardLimitGrace,
 			pvcStorage:         pvcStorage,
 		}
-		iErr := p.createQuotaPod(ctx, podOpts)
+		projectID, iErr := p.applyQuota(ctx, podOpts)
 		if iErr != nil {
 			klog.Infof("Applying quota failed: %v", iErr)
 			alertlog.Logger.Errorw("",
-				"eventcode", "local.pv.provision.failure",
+				"eventcode", eventCodeProvisionFailure,
 				"msg", "Failed to provision Local PV",
 				"rname", opts.PVName,
 				"reason", "Quota enforcement failed",
 				"storagetype", stgType,
 			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Quota enforcement failed: %v", iErr)
+			countFailure(eventCodeProvisionFailure, "Quota enforcement failed")
 			return nil, pvController.ProvisioningFinished, iErr
 		}
 		alertlog.Logger.Infow("",
-			"eventcode", "local.pv.quota.success",
+			"eventcode", eventCodeQuotaSuccess,
 			"msg", "Successfully applied quota",
 			"rname", opts.PVName,
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeNormal, eventCodeQuotaSuccess, "Successfully applied quota")
+		quotaProjectID = projectID
+		quotaSoftLimitGrace, quotaHardLimitGrace = softLimitGrace, hardLimitGrace
+	}
+
+	// The data of the clone source volume or snapshot is copied after
+	// the quota is applied, so that the copied files are accounted to
+	// the quota project of the volume.
+	if hasVolumeDataSource(pvc) {
+		source, err := p.getVolumeDataSource(ctx, pvc)
+		if err != nil {
+			return nil, pvController.ProvisioningFinished, err
+		}
+
+		klog.Infof("Populating volume %v from %v:%v", name, source.name, source.path)
+		podOpts := &HelperPodOptions{
+			name:               name,
+			pvName:             name,
+			pvcName:            opts.PVC.Name,
+			pvcNamespace:       opts.PVC.Namespace,
+			eventObject:        pvc,
+			path:               path,
+			rootPath:           rootPath,
+			sourcePath:         source.path,
+			sourceMode:         source.mode,
+			dirMode:            dirMode,
+			dirUID:             dirUID,
+			dirGID:             dirGID,
+			dirDefaultACL:      dirDefaultACL,
+			selinuxContext:     selinuxContext,
+			nodeAffinityLabels: nodeAffinityLabels,
+			serviceAccountName: saName,
+			selectedNodeTaints: taints,
+			imagePullSecrets:   imagePullSecrets,
+			helperImage:        volumeConfig.GetHelperImage(),
+			podTemplate:        volumeConfig.GetHelperPodTemplate(),
+		}
+		if cErr := p.createClonePod(ctx, podOpts); cErr != nil {
+			klog.Infof("Populating volume %v failed: %v", name, cErr)
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeProvisionFailure,
+				"msg", "Failed to provision Local PV",
+				"rname", opts.PVName,
+				"reason", "Volume population from dataSource failed",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: Volume population from dataSource failed: %v", cErr)
+			countFailure(eventCodeProvisionFailure, "Volume population from dataSource failed")
+			return nil, pvController.ProvisioningFinished, cErr
+		}
+		alertlog.Logger.Infow("",
+			"eventcode", eventCodeCloneSuccess,
+			"msg", "Successfully populated Local PV from dataSource",
+			"rname", opts.PVName,
+			"source", source.name,
+			"storagetype", stgType,
+		)
+		p.recordEvent(pvc, v1.EventTypeNormal, eventCodeCloneSuccess, "Successfully populated Local PV from dataSource")
 	}
 
 	// VolumeMode will always be specified as Filesystem for host path volume,
@@ -180,11 +433,39 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 	// Set the Local PV to create it.
 	//hostPathType := v1.HostPathDirectoryOrCreate
 
-	// TODO initialize the Labels and annotations
 	// Use annotations to specify the context using which the PV was created.
-	//volAnnotations := make(map[string]string)
-	//volAnnotations[string(v1alpha1.CASTypeKey)] = casVolume.Spec.CasType
-	//fstype := casVolume.Spec.FSType
+	volAnnotations := make(map[string]string)
+	volAnnotations[rootPathAnnotation] = rootPath
+	if isAbsolutePath {
+		volAnnotations[absolutePathAnnotation] = "true"
+	} else {
+		if seed != nil {
+			volAnnotations[seedAnnotation] = seed.String()
+		}
+		volAnnotations[directoryModeAnnotation] = dirMode
+		for annotation, value := range map[string]string{
+			directoryUIDAnnotation:        dirUID,
+			directoryGIDAnnotation:        dirGID,
+			directoryDefaultACLAnnotation: dirDefaultACL,
+			selinuxContextAnnotation:      selinuxContext,
+		} {
+			if value != "" {
+				volAnnotations[annotation] = value
+			}
+		}
+		if wipePolicy != WipePolicyNone {
+			volAnnotations[wipePolicyAnnotation] = wipePolicy
+		}
+		if quotaProjectID != 0 {
+			volAnnotations[quotaProjectIDAnnotation] = strconv.FormatUint(uint64(quotaProjectID), 10)
+			if quotaSoftLimitGrace != "" {
+				volAnnotations[quotaSoftLimitGraceAnnotation] = quotaSoftLimitGrace
+			}
+			if quotaHardLimitGrace != "" {
+				volAnnotations[quotaHardLimitGraceAnnotation] = quotaHardLimitGrace
+			}
+		}
+	}
 
 	labels := make(map[string]string)
 	labels[string(mconfig.CASTypeKey)] = "local-" + stgType
@@ -194,6 +475,7 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 	pvObj, err := persistentvolume.NewBuilder().
 		WithName(name).
 		WithLabels(labels).
+		WithAnnotations(volAnnotations).
 		WithReclaimPolicy(*opts.StorageClass.ReclaimPolicy).
 		WithAccessModes(pvc.Spec.AccessModes).
 		WithVolumeMode(fs).
@@ -204,23 +486,53 @@ func (p *Provisioner) ProvisionHostPath(ctx context.Context, opts pvController.P
 
 	if err != nil {
 		alertlog.Logger.Errorw("",
-			"eventcode", "local.pv.provision.failure",
+			"eventcode", eventCodeProvisionFailure,
 			"msg", "Failed to provision Local PV",
 			"rname", opts.PVName,
 			"reason", "failed to build persistent volume",
 			"storagetype", stgType,
 		)
+		p.recordEvent(pvc, v1.EventTypeWarning, eventCodeProvisionFailure, "Failed to provision Local PV: failed to build persistent volume: %v", err)
+		countFailure(eventCodeProvisionFailure, "failed to build persistent volume")
 		return nil, pvController.ProvisioningFinished, err
 	}
 	alertlog.Logger.Infow("",
-		"eventcode", "local.pv.provision.success",
+		"eventcode", eventCodeProvisionSuccess,
 		"msg", "Successfully provisioned Local PV",
 		"rname", opts.PVName,
 		"storagetype", stgType,
 	)
+	p.recordEvent(pvc, v1.EventTypeNormal, eventCodeProvisionSuccess, "Successfully provisioned Local PV")
 	return pvObj, pvController.ProvisioningFinished, nil
 }
 
+// checkPathInUse returns an error if the path is the same as, contains,
+// or is contained in the path of a hostpath PV on the node with the
+// given affinity labels.
+func (p *Provisioner) checkPathInUse(ctx context.Context, path string, nodeAffinityLabels map[string]string) error {
+	pvList, err := p.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
+		LabelSelector: string(mconfig.CASTypeKey) + "=local-hostpath",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list persistentvolumes")
+	}
+
+	for i := range pvList.Items {
+		pvObj := persistentvolume.NewForAPIObject(&pvList.Items[i])
+		if !reflect.DeepEqual(pvObj.GetAffinitedNodeLabels(), nodeAffinityLabels) {
+			continue
+		}
+		pvPath := pvObj.GetPath()
+		if pvPath == "" {
+			continue
+		}
+		if filepath.Clean(pvPath) == filepath.Clean(path) || isSubPath(pvPath, path) || isSubPath(path, pvPath) {
+			return errors.Errorf("path %v overlaps with the path %v of volume %v", path, pvPath, pvList.Items[i].Name)
+		}
+	}
+	return nil
+}
+
 // GetNodeObjectFromLabels returns the Node Object with matching label key and value
 func (p *Provisioner) GetNodeObjectFromLabels(nodeLabels map[string]string) (*v1.Node, error) {
 	labelSelector := metav1.LabelSelector{MatchLabels: nodeLabels}
@@ -228,12 +540,15 @@ func (p *Provisioner) GetNodeObjectFromLabels(nodeLabels map[string]string) (*v1
 		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
 	}
 	nodeList, err := p.kubeClient.CoreV1().Nodes().List(context.TODO(), listOptions)
-	if err != nil || len(nodeList.Items) == 0 {
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to get the Node with the Node Labels {%v}", nodeLabels)
+	}
+	if len(nodeList.Items) == 0 {
 		// After the PV is created and node affinity is set
 		// based on kubernetes.io/hostname label, either:
 		// - hostname label changed on the node or
 		// - the node is deleted from the cluster.
-		return nil, errors.Errorf("Unable to get the Node with the Node Labels {%v}", nodeLabels)
+		return nil, &nodeNotFoundError{nodeLabels: nodeLabels}
 	}
 	if len(nodeList.Items) != 1 {
 		// After the PV is created and node affinity is set
@@ -263,6 +578,13 @@ func (p *Provisioner) DeleteHostPath(ctx context.Context, pv *v1.PersistentVolum
 		return errors.Errorf("no HostPath set")
 	}
 
+	// The directory of an AbsolutePath volume existed before the volume
+	// was provisioned and may be shared with other volumes.
+	if pv.Annotations[absolutePathAnnotation] == "true" {
+		klog.Infof("Skipping clean up of volume %v with absolute path %v", pv.Name, path)
+		return nil
+	}
+
 	nodeAffinityLabels := pvObj.GetAffinitedNodeLabels()
 	if len(nodeAffinityLabels) == 0 {
 		return errors.Errorf("cannot find affinited node details")
@@ -271,6 +593,9 @@ func (p *Provisioner) DeleteHostPath(ctx context.Context, pv *v1.PersistentVolum
 
 	//Get the node Object once again to get updated Taints.
 	nodeObject, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if isNodeNotFound(err) {
+		return p.deleteWithNodeGone(ctx, pv, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -284,15 +609,139 @@ func (p *Provisioner) DeleteHostPath(ctx context.Context, pv *v1.PersistentVolum
 	podOpts := &HelperPodOptions{
 		cmdsForPath:        cleanupCmdsForPath,
 		name:               pv.Name,
+		pvName:             pv.Name,
+		eventObject:        pv,
 		path:               path,
+		rootPath:           pv.Annotations[rootPathAnnotation],
 		nodeAffinityLabels: nodeAffinityLabels,
 		serviceAccountName: saName,
 		selectedNodeTaints: taints,
 		imagePullSecrets:   imagePullSecrets,
+		wipePolicy:         getWipePolicy(pv),
+	}
+	if pv.Spec.ClaimRef != nil {
+		podOpts.pvcName = pv.Spec.ClaimRef.Name
+		podOpts.pvcNamespace = pv.Spec.ClaimRef.Namespace
+	}
+	scConfig, err := p.getStorageClassConfig(ctx, pv)
+	if err != nil {
+		return err
+	}
+	if scConfig != nil {
+		podOpts.helperImage = scConfig.GetHelperImage()
+		podOpts.podTemplate = scConfig.GetHelperPodTemplate()
 	}
 
-	if err := p.createCleanupPod(ctx, podOpts); err != nil {
+	trash, err := p.getTrashPolicy(ctx, pv)
+	if err != nil {
+		return err
+	}
+	if trash != nil {
+		return p.trashHostPath(ctx, pv, trash, podOpts)
+	}
+
+	if err := p.cleanupHostPath(ctx, pv, podOpts); err != nil {
 		return errors.Wrapf(err, "clean up volume %v failed", pv.Name)
 	}
+	alertlog.Logger.Infow("",
+		"eventcode", eventCodeCleanupSuccess,
+		"msg", "Successfully cleaned up Local PV",
+		"rname", pv.Name,
+	)
+	p.recordEvent(pv, v1.EventTypeNormal, eventCodeCleanupSuccess, "Successfully cleaned up Local PV")
 	return nil
 }
+
+// ExpandHostPath is invoked by the ResizeController to grow a hostpath
+//
+//	volume. If XFS or EXT4 project quota was applied to the volume at
+//	provisioning time, i.e. the PV has a quota project ID, the soft and
+//	hard limits are recomputed for the new size with the limit grace of
+//	the PV, and applied to the project set on the volume directory. The
+//	quota settings of the StorageClass are not used, as they may have
+//	changed after the volume was provisioned. The PV capacity is updated
+//	only after the limits are in place.
+func (p *Provisioner) ExpandHostPath(ctx context.Context, pv *v1.PersistentVolume, pvc *v1.PersistentVolumeClaim, newSize resource.Quantity) (*v1.PersistentVolume, error) {
+	volumeConfig, err := p.getVolumeConfig(ctx, pv.Name, pvc)
+	if err != nil {
+		return nil, err
+	}
+	stgType := volumeConfig.GetStorageType()
+	saName := getOpenEBSServiceAccountName()
+
+	if value, ok := pv.Annotations[quotaProjectIDAnnotation]; ok {
+		quotaProjectID, err := parseQuotaProjectID(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get quota project of volume %v", pv.Name)
+		}
+
+		pvObj := persistentvolume.NewForAPIObject(pv)
+		path := pvObj.GetPath()
+		if path == "" {
+			return nil, errors.Errorf("no HostPath set")
+		}
+
+		nodeAffinityLabels := pvObj.GetAffinitedNodeLabels()
+		if len(nodeAffinityLabels) == 0 {
+			return nil, errors.Errorf("cannot find affinited node details")
+		}
+
+		//Get the node Object to get updated Taints.
+		nodeObject, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+		if err != nil {
+			return nil, err
+		}
+
+		klog.Infof("Expanding volume %v at %v:%v to %v", pv.Name, GetNodeHostname(nodeObject), path, newSize.String())
+		podOpts := &HelperPodOptions{
+			name:               pv.Name,
+			pvName:             pv.Name,
+			pvcName:            pvc.Name,
+			pvcNamespace:       pvc.Namespace,
+			eventObject:        pvc,
+			path:               path,
+			nodeAffinityLabels: nodeAffinityLabels,
+			serviceAccountName: saName,
+			selectedNodeTaints: GetTaints(nodeObject),
+			imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+			helperImage:        volumeConfig.GetHelperImage(),
+			podTemplate:        volumeConfig.GetHelperPodTemplate(),
+			softLimitGrace:     pv.Annotations[quotaSoftLimitGraceAnnotation],
+			hardLimitGrace:     pv.Annotations[quotaHardLimitGraceAnnotation],
+			pvcStorage:         newSize.Value(),
+			quotaProjectID:     quotaProjectID,
+		}
+		if err := p.createQuotaResizePod(ctx, podOpts); err != nil {
+			klog.Infof("Updating quota failed: %v", err)
+			alertlog.Logger.Errorw("",
+				"eventcode", eventCodeResizeFailure,
+				"msg", "Failed to resize Local PV",
+				"rname", pv.Name,
+				"reason", "Quota update failed",
+				"storagetype", stgType,
+			)
+			p.recordEvent(pvc, v1.EventTypeWarning, eventCodeResizeFailure, "Failed to resize Local PV: Quota update failed: %v", err)
+			countFailure(eventCodeResizeFailure, "Quota update failed")
+			return nil, errors.Wrapf(err, "failed to update quota of volume %v", pv.Name)
+		}
+	}
+
+	pv = pv.DeepCopy()
+	if pv.Spec.Capacity == nil {
+		pv.Spec.Capacity = v1.ResourceList{}
+	}
+	pv.Spec.Capacity[v1.ResourceStorage] = newSize
+	pv, err = p.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update capacity of volume %v", pvc.Spec.VolumeName)
+	}
+
+	alertlog.Logger.Infow("",
+		"eventcode", eventCodeResizeSuccess,
+		"msg", "Successfully resized Local PV",
+		"rname", pv.Name,
+		"storagetype", stgType,
+	)
+	p.recordEvent(pvc, v1.EventTypeNormal, eventCodeResizeSuccess, "Successfully resized Local PV")
+	return pv, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"testing"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func TestCheckPathInUse(t *testing.T) {
+	fakePV := func(name, node, path string) runtime.Object {
+		pv, err := persistentvolume.NewBuilder().
+			WithName(name).
+			WithLabels(map[string]string{string(mconfig.CASTypeKey): "local-hostpath"}).
+			WithCapacityQty(resource.MustParse("1Gi")).
+			WithLocalHostDirectory(path).
+			WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: node}).
+			Build()
+		if err != nil {
+			t.Fatalf("failed to build pv: %v", err)
+		}
+		return pv
+	}
+	pvs := []runtime.Object{
+		fakePV("pv1", "node-1", "/var/openebs/local/demo/data-web-0"),
+		fakePV("pv2", "node-1", "/var/openebs/local/shared"),
+		fakePV("pv3", "node-2", "/var/openebs/local/demo/data-web-1"),
+	}
+
+	testCases := map[string]struct {
+		path        string
+		node        string
+		expectError bool
+	}{
+		"unused path":             {path: "/var/openebs/local/demo/data-web-1", node: "node-1"},
+		"same path on other node": {path: "/var/openebs/local/demo/data-web-0", node: "node-2"},
+		"same path":               {path: "/var/openebs/local/demo/data-web-0", node: "node-1", expectError: true},
+		"same path not clean":     {path: "/var/openebs/local/demo/data-web-0/", node: "node-1", expectError: true},
+		"nested in a volume":      {path: "/var/openebs/local/shared/pvc-0001", node: "node-1", expectError: true},
+		"contains a volume":       {path: "/var/openebs/local/demo", node: "node-1", expectError: true},
+		"sibling prefix":          {path: "/var/openebs/local/shared-2", node: "node-1"},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			p := &Provisioner{kubeClient: fake.NewSimpleClientset(pvs...)}
+			err := p.checkPathInUse(context.Background(), v.path, map[string]string{k8sNodeLabelKeyHostname: v.node})
+			if v.expectError != (err != nil) {
+				t.Errorf("expected error %v, but got %v", v.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openebs/maya/pkg/alertlog"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+)
+
+const (
+	// NodeGonePolicyFail fails the delete of the volume, which is
+	// retried till the provisioner gives up.
+	NodeGonePolicyFail = "Fail"
+	// NodeGonePolicyForget deletes the PV without cleaning up the volume
+	// once the node has been gone for the grace period.
+	NodeGonePolicyForget = "Forget"
+	// NodeGonePolicyWait keeps the PV till a node with matching labels
+	// returns. The delete is retried at every resync of the PV.
+	NodeGonePolicyWait = "Wait"
+
+	// nodeGoneSinceAnnotation is set on the PV with the time at which
+	// the node of the volume was first found to be gone.
+	nodeGoneSinceAnnotation = "local.openebs.io/node-gone-since"
+
+	// nodeGoneReason is the reason of the status of the PV, and of the
+	// condition of the PVC, whose node is gone.
+	nodeGoneReason = "NodeGone"
+)
+
+// nodeNotFoundError is returned by GetNodeObjectFromLabels if no node
+// matches the labels.
+type nodeNotFoundError struct {
+	nodeLabels map[string]string
+}
+
+func (e *nodeNotFoundError) Error() string {
+	return fmt.Sprintf("Unable to get the Node with the Node Labels {%v}", e.nodeLabels)
+}
+
+// isNodeNotFound returns true if the error is a nodeNotFoundError.
+func isNodeNotFound(err error) bool {
+	_, ok := errors.Cause(err).(*nodeNotFoundError)
+	return ok
+}
+
+// deleteWithNodeGone applies the node gone policy of the StorageClass
+// of the PV whose node is gone. The PV, and its PVC if it still
+// exists, are marked with the NodeGone reason.
+func (p *Provisioner) deleteWithNodeGone(ctx context.Context, pv *v1.PersistentVolume, nodeErr error) error {
+	policy, gracePeriod := NodeGonePolicyFail, time.Duration(0)
+	volumeConfig, err := p.getStorageClassConfig(ctx, pv)
+	if err != nil {
+		return err
+	}
+	if volumeConfig != nil {
+		policy, gracePeriod, err = volumeConfig.GetNodeGonePolicy()
+		if err != nil {
+			return err
+		}
+	}
+
+	since := time.Now()
+	if value, ok := pv.Annotations[nodeGoneSinceAnnotation]; ok {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			since = parsed
+		}
+	}
+
+	var message string
+	switch policy {
+	case NodeGonePolicyForget:
+		forgetAt := since.Add(gracePeriod)
+		if !time.Now().Before(forgetAt) {
+			p.recordEvent(pv, v1.EventTypeWarning, eventCodeNodeGone,
+				"Node of the volume is gone since %v, deleting the volume without cleaning up its data", since.Format(time.RFC3339))
+			klog.Warningf("Forgetting volume %v, node gone since %v: %v", pv.Name, since.Format(time.RFC3339), nodeErr)
+			return nil
+		}
+		message = fmt.Sprintf("%v. The volume will be deleted without cleaning up its data after %v", nodeErr, forgetAt.Format(time.RFC3339))
+	case NodeGonePolicyWait:
+		message = fmt.Sprintf("%v. The volume will be deleted when a node with matching labels returns", nodeErr)
+	default:
+		message = nodeErr.Error()
+	}
+
+	alertlog.Logger.Errorw("",
+		"eventcode", eventCodeNodeGone,
+		"msg", "Node of Local PV is gone",
+		"rname", pv.Name,
+		"reason", "Node gone",
+		"nodegonepolicy", policy,
+	)
+	if policy != NodeGonePolicyWait {
+		countFailure(eventCodeNodeGone, "Node gone")
+	}
+	if err := p.markNodeGone(ctx, pv, since, message); err != nil {
+		klog.Errorf("Failed to mark the node of volume %v as gone: %v", pv.Name, err)
+	}
+	if policy == NodeGonePolicyWait {
+		p.recordEvent(pv, v1.EventTypeWarning, eventCodeNodeGone, "%v", message)
+		return &pvController.IgnoredError{Reason: message}
+	}
+	return errors.New(message)
+}
+
+// markNodeGone records the time since which the node of the PV is gone,
+// and sets the NodeGone reason and message on the status of the PV and
+// as a condition of its PVC.
+func (p *Provisioner) markNodeGone(ctx context.Context, pv *v1.PersistentVolume, since time.Time, message string) error {
+	pvClient := p.kubeClient.CoreV1().PersistentVolumes()
+	latest, err := pvClient.Get(ctx, pv.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if _, ok := latest.Annotations[nodeGoneSinceAnnotation]; !ok {
+		if latest.Annotations == nil {
+			latest.Annotations = map[string]string{}
+		}
+		latest.Annotations[nodeGoneSinceAnnotation] = since.UTC().Format(time.RFC3339)
+		latest, err = pvClient.Update(ctx, latest, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	if latest.Status.Reason != nodeGoneReason || latest.Status.Message != message {
+		latest.Status.Reason = nodeGoneReason
+		latest.Status.Message = message
+		if _, err := pvClient.UpdateStatus(ctx, latest, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	claimRef := pv.Spec.ClaimRef
+	if claimRef == nil {
+		return nil
+	}
+	pvcClient := p.kubeClient.CoreV1().PersistentVolumeClaims(claimRef.Namespace)
+	pvc, err := pvcClient.Get(ctx, claimRef.Name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if claimRef.UID != "" && pvc.UID != claimRef.UID {
+		return nil
+	}
+	condition := v1.PersistentVolumeClaimCondition{
+		Type:               v1.PersistentVolumeClaimConditionType(nodeGoneReason),
+		Status:             v1.ConditionTrue,
+		Reason:             nodeGoneReason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i, existing := range pvc.Status.Conditions {
+		if existing.Type == condition.Type {
+			if existing.Message == message {
+				return nil
+			}
+			pvc.Status.Conditions = append(pvc.Status.Conditions[:i], pvc.Status.Conditions[i+1:]...)
+			break
+		}
+	}
+	pvc.Status.Conditions = append(pvc.Status.Conditions, condition)
+	_, err = pvcClient.UpdateStatus(ctx, pvc, metav1.UpdateOptions{})
+	return err
+}
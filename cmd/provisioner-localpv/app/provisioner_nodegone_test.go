@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func TestDeleteWithNodeGone(t *testing.T) {
+	nodeGoneSC := func(policy string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "openebs-hostpath",
+				Annotations: map[string]string{
+					string(mconfig.CASConfigKey): `
+- name: StorageType
+  value: "hostpath"
+- name: NodeGonePolicy
+  value: "` + policy + `"
+  data:
+    gracePeriod: "1h"
+`,
+				},
+			},
+			Provisioner: provisionerName,
+		}
+	}
+	newPV := func(goneSince string) *v1.PersistentVolume {
+		pv, _ := persistentvolume.NewBuilder().
+			WithName("pvName").
+			WithLocalHostDirectory("/var/openebs/local/pvName").
+			WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: "gone"}).
+			Build()
+		pv.Spec.StorageClassName = "openebs-hostpath"
+		pv.Spec.ClaimRef = &v1.ObjectReference{Namespace: "app", Name: "pvcName", UID: "pvc-uid"}
+		pv.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimDelete
+		if goneSince != "" {
+			pv.Annotations = map[string]string{nodeGoneSinceAnnotation: goneSince}
+		}
+		return pv
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvcName", Namespace: "app", UID: "pvc-uid"},
+	}
+
+	testCases := map[string]struct {
+		sc            *storagev1.StorageClass
+		goneSince     string
+		expectError   bool
+		expectIgnored bool
+		expectMarked  bool
+		expectEvent   string
+	}{
+		"fail": {
+			sc:           nodeGoneSC("Fail"),
+			expectError:  true,
+			expectMarked: true,
+		},
+		"no storageclass": {
+			expectError:  true,
+			expectMarked: true,
+		},
+		"wait": {
+			sc:            nodeGoneSC("Wait"),
+			expectError:   true,
+			expectIgnored: true,
+			expectMarked:  true,
+			expectEvent:   "Warning " + eventCodeNodeGone,
+		},
+		"forget within grace period": {
+			sc:           nodeGoneSC("Forget"),
+			expectError:  true,
+			expectMarked: true,
+		},
+		"forget after grace period": {
+			sc:          nodeGoneSC("Forget"),
+			goneSince:   time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+			expectEvent: "Warning " + eventCodeNodeGone,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pv := newPV(tc.goneSince)
+			objects := []runtime.Object{pv, pvc}
+			if tc.sc != nil {
+				objects = append(objects, tc.sc)
+			}
+			recorder := record.NewFakeRecorder(10)
+			p := &Provisioner{
+				kubeClient: fake.NewSimpleClientset(objects...),
+				recorder:   recorder,
+			}
+			p.getVolumeConfig = p.GetVolumeConfig
+
+			err := p.Delete(context.TODO(), pv)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected error, got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, ok := err.(*pvController.IgnoredError); ok != tc.expectIgnored {
+				t.Errorf("expected ignored error %v, got %v", tc.expectIgnored, err)
+			}
+
+			latest, _ := p.kubeClient.CoreV1().PersistentVolumes().Get(context.TODO(), "pvName", metav1.GetOptions{})
+			latestPVC, _ := p.kubeClient.CoreV1().PersistentVolumeClaims("app").Get(context.TODO(), "pvcName", metav1.GetOptions{})
+			marked := latest.Annotations[nodeGoneSinceAnnotation] != "" && latest.Status.Reason == nodeGoneReason &&
+				len(latestPVC.Status.Conditions) == 1 && latestPVC.Status.Conditions[0].Reason == nodeGoneReason
+			if marked != tc.expectMarked {
+				t.Errorf("expected pv and pvc marked %v, got pv %+v and pvc conditions %+v",
+					tc.expectMarked, latest.Status, latestPVC.Status.Conditions)
+			}
+
+			close(recorder.Events)
+			var nodeGoneEvent string
+			var deleteFailed bool
+			for event := range recorder.Events {
+				if strings.Contains(event, eventCodeNodeGone) {
+					nodeGoneEvent = event
+				}
+				if strings.HasPrefix(event, "Warning "+eventCodeDeleteFailure) {
+					deleteFailed = true
+				}
+			}
+			if !strings.HasPrefix(nodeGoneEvent, tc.expectEvent) || (tc.expectEvent == "" && nodeGoneEvent != "") {
+				t.Errorf("expected event %q, got %q", tc.expectEvent, nodeGoneEvent)
+			}
+			if deleteFailed != tc.expectError {
+				t.Errorf("expected delete failure event %v, got %v", tc.expectError, deleteFailed)
+			}
+		})
+	}
+}
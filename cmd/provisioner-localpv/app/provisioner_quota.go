@@ -0,0 +1,293 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// quotaProjectIDAnnotation is set on the hostpath PV with the XFS or
+	// EXT4 quota project ID of the volume directory.
+	quotaProjectIDAnnotation = "local.openebs.io/quota-project-id"
+
+	// quotaSoftLimitGraceAnnotation and quotaHardLimitGraceAnnotation are
+	// set on the hostpath PV with the softLimitGrace and hardLimitGrace
+	// of the quota applied at provisioning time, so that the volume is
+	// expanded with the same limits even if the StorageClass changes.
+	quotaSoftLimitGraceAnnotation = "local.openebs.io/quota-soft-limit-grace"
+	quotaHardLimitGraceAnnotation = "local.openebs.io/quota-hard-limit-grace"
+
+	// quotaProjectInUseExitCode is the exit code of the quota helper pod
+	// if the project ID allocated to the volume is in use by another
+	// directory. The pod then logs quotaProjectInUseMessage, followed by
+	// the project IDs in use on the filesystem.
+	quotaProjectInUseExitCode = 3
+	quotaProjectInUseMessage  = "quota project IDs in use:"
+
+	// minQuotaProjectID and maxQuotaProjectID bound the allocated project
+	// IDs. Project ID 0 is the default project of all the files.
+	minQuotaProjectID = uint32(1)
+	maxQuotaProjectID = uint32(math.MaxUint32 - 1)
+
+	// quotaProjectAttempts is the number of project IDs tried on a node
+	// before the quota is reported as failed.
+	quotaProjectAttempts = 3
+)
+
+// quotaProjectReservationTTL is the time for which a project ID
+// allocated to a volume is reserved, till its PV is created. It is
+// past the time a Provision can take.
+var quotaProjectReservationTTL = provisionStallTimeout
+
+// quotaProjectAllocator allocates the quota project IDs of the hostpath
+// volumes. The project IDs in use on a node are read from the
+// annotations of its PVs, so that allocation survives a restart of the
+// provisioner, and the lowest free project ID is allocated, so that the
+// project IDs of deleted volumes are reused. Allocation is serialized
+// per node, as the project ID is reserved till the PV is created.
+type quotaProjectAllocator struct {
+	sync.Mutex
+	// nodes serializes the allocation of the project IDs on each node.
+	nodes map[string]*sync.Mutex
+	// reserved has the project IDs allocated to volumes whose PVs are
+	// not created yet, by node and PV name.
+	reserved map[string]map[string]quotaProjectReservation
+	// inUse has the project IDs found in use on a node by directories
+	// that are not volumes with the annotation, e.g. volumes provisioned
+	// before the project IDs were allocated by the provisioner.
+	inUse map[string]map[uint32]bool
+}
+
+type quotaProjectReservation struct {
+	id      uint32
+	expires time.Time
+}
+
+func newQuotaProjectAllocator() *quotaProjectAllocator {
+	return &quotaProjectAllocator{
+		nodes:    map[string]*sync.Mutex{},
+		reserved: map[string]map[string]quotaProjectReservation{},
+		inUse:    map[string]map[uint32]bool{},
+	}
+}
+
+// lockNode locks the allocation on the node till the returned func is
+// called.
+func (a *quotaProjectAllocator) lockNode(node string) func() {
+	a.Lock()
+	nodeLock, ok := a.nodes[node]
+	if !ok {
+		nodeLock = &sync.Mutex{}
+		a.nodes[node] = nodeLock
+	}
+	a.Unlock()
+	nodeLock.Lock()
+	return nodeLock.Unlock
+}
+
+// allocate returns the project ID of the volume on the node, given the
+// project IDs of the PVs on the node. The project ID already reserved
+// for the volume is returned if the volume is provisioned again. The
+// node must be locked.
+func (a *quotaProjectAllocator) allocate(node, pvName string, pvIDs map[string]uint32, now time.Time) (uint32, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	used := map[uint32]bool{}
+	for id := range a.inUse[node] {
+		used[id] = true
+	}
+	for _, id := range pvIDs {
+		used[id] = true
+	}
+	for name, reservation := range a.reserved[node] {
+		if _, ok := pvIDs[name]; ok || now.After(reservation.expires) {
+			delete(a.reserved[node], name)
+			continue
+		}
+		if name != pvName {
+			used[reservation.id] = true
+		}
+	}
+
+	if reservation, ok := a.reserved[node][pvName]; ok && !a.inUse[node][reservation.id] {
+		return reservation.id, nil
+	}
+	for id := minQuotaProjectID; id <= maxQuotaProjectID; id++ {
+		if !used[id] {
+			return id, nil
+		}
+	}
+	return 0, errors.Errorf("no free quota project ID on node %v", node)
+}
+
+// reserve reserves the project ID for the volume on the node till its
+// PV is created.
+func (a *quotaProjectAllocator) reserve(node, pvName string, id uint32, now time.Time) {
+	a.Lock()
+	defer a.Unlock()
+	if a.reserved[node] == nil {
+		a.reserved[node] = map[string]quotaProjectReservation{}
+	}
+	a.reserved[node][pvName] = quotaProjectReservation{id: id, expires: now.Add(quotaProjectReservationTTL)}
+}
+
+// markInUse excludes the project IDs found in use on the node from
+// allocation.
+func (a *quotaProjectAllocator) markInUse(node string, ids []uint32) {
+	a.Lock()
+	defer a.Unlock()
+	if a.inUse[node] == nil {
+		a.inUse[node] = map[uint32]bool{}
+	}
+	for _, id := range ids {
+		a.inUse[node][id] = true
+	}
+}
+
+// applyQuota allocates a project ID to the volume directory, and
+// launches the quota helper pod to apply the limits of the volume with
+// it. The quota helper pod verifies that the project ID is not in use
+// on the node before setting it. If it is, another project ID is tried.
+// It returns the project ID of the volume.
+func (p *Provisioner) applyQuota(ctx context.Context, pOpts *HelperPodOptions) (uint32, error) {
+	node := quotaProjectNode(pOpts.nodeAffinityLabels)
+
+	var qErr error
+	for attempt := 0; attempt < quotaProjectAttempts; attempt++ {
+		id, err := p.reserveQuotaProject(ctx, node, pOpts)
+		if err != nil {
+			return 0, err
+		}
+		pOpts.quotaProjectID = id
+		qErr = p.createQuotaPod(ctx, pOpts)
+		if qErr == nil {
+			return id, nil
+		}
+		inUse, ok := getQuotaProjectsInUse(qErr)
+		if !ok {
+			return 0, qErr
+		}
+		klog.Infof("Quota project ID %v of volume %v is in use on node %v, trying another", id, pOpts.name, node)
+		p.quotaProjects.markInUse(node, append(inUse, id))
+	}
+	return 0, qErr
+}
+
+// reserveQuotaProject allocates a project ID to the volume on the node,
+// and reserves it till the PV of the volume is created. The node is
+// locked only while the project ID is allocated, so that the quota of
+// other volumes of the node is applied in parallel with distinct
+// project IDs.
+func (p *Provisioner) reserveQuotaProject(ctx context.Context, node string, pOpts *HelperPodOptions) (uint32, error) {
+	defer p.quotaProjects.lockNode(node)()
+
+	pvIDs, err := p.getQuotaProjectIDs(ctx, pOpts.nodeAffinityLabels)
+	if err != nil {
+		return 0, err
+	}
+	id, err := p.quotaProjects.allocate(node, pOpts.name, pvIDs, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	p.quotaProjects.reserve(node, pOpts.name, id, time.Now())
+	return id, nil
+}
+
+// getQuotaProjectIDs returns the project IDs of the hostpath PVs on the
+// node with the given affinity labels, by PV name.
+func (p *Provisioner) getQuotaProjectIDs(ctx context.Context, nodeAffinityLabels map[string]string) (map[string]uint32, error) {
+	pvList, err := p.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{
+		LabelSelector: string(mconfig.CASTypeKey) + "=local-hostpath",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list persistentvolumes")
+	}
+
+	ids := map[string]uint32{}
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		value, ok := pv.Annotations[quotaProjectIDAnnotation]
+		if !ok || !reflect.DeepEqual(persistentvolume.NewForAPIObject(pv).GetAffinitedNodeLabels(), nodeAffinityLabels) {
+			continue
+		}
+		id, err := parseQuotaProjectID(value)
+		if err != nil {
+			klog.Warningf("Ignoring quota project ID of volume %v: %v", pv.Name, err)
+			continue
+		}
+		ids[pv.Name] = id
+	}
+	return ids, nil
+}
+
+// quotaProjectNode returns the key of the node with the given affinity
+// labels.
+func quotaProjectNode(nodeAffinityLabels map[string]string) string {
+	keys := make([]string, 0, len(nodeAffinityLabels))
+	for key, value := range nodeAffinityLabels {
+		keys = append(keys, key+"="+value)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// parseQuotaProjectID parses the value of the quotaProjectIDAnnotation.
+func parseQuotaProjectID(value string) (uint32, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || uint32(id) < minQuotaProjectID || uint32(id) > maxQuotaProjectID {
+		return 0, errors.Errorf("invalid quota project ID %q", value)
+	}
+	return uint32(id), nil
+}
+
+// getQuotaProjectsInUse returns the project IDs in use on the node, if
+// the error is from a quota helper pod that found the project ID of
+// the volume in use.
+func getQuotaProjectsInUse(err error) ([]uint32, bool) {
+	podErr, ok := errors.Cause(err).(*helperPodError)
+	if !ok || podErr.exitCode != quotaProjectInUseExitCode {
+		return nil, false
+	}
+	index := strings.LastIndex(podErr.logs, quotaProjectInUseMessage)
+	if index < 0 {
+		return nil, true
+	}
+	line := strings.SplitN(podErr.logs[index+len(quotaProjectInUseMessage):], "\n", 2)[0]
+	var ids []uint32
+	for _, field := range strings.Fields(line) {
+		if id, err := parseQuotaProjectID(field); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestQuotaProjectAllocate(t *testing.T) {
+	now := time.Now()
+	testCases := map[string]struct {
+		pvIDs    map[string]uint32
+		reserved map[string]quotaProjectReservation
+		inUse    []uint32
+		pvName   string
+		expectID uint32
+	}{
+		"first volume": {
+			pvName:   "pvc-0001",
+			expectID: 1,
+		},
+		"lowest free ID": {
+			pvIDs:    map[string]uint32{"pvc-0001": 1, "pvc-0003": 3},
+			pvName:   "pvc-0004",
+			expectID: 2,
+		},
+		"reserved for another volume": {
+			pvIDs:    map[string]uint32{"pvc-0001": 1},
+			reserved: map[string]quotaProjectReservation{"pvc-0002": {id: 2, expires: now.Add(time.Hour)}},
+			pvName:   "pvc-0003",
+			expectID: 3,
+		},
+		"reserved for the volume": {
+			reserved: map[string]quotaProjectReservation{"pvc-0002": {id: 5, expires: now.Add(time.Hour)}},
+			pvName:   "pvc-0002",
+			expectID: 5,
+		},
+		"reservation of a created PV": {
+			pvIDs:    map[string]uint32{"pvc-0001": 2},
+			reserved: map[string]quotaProjectReservation{"pvc-0001": {id: 1, expires: now.Add(time.Hour)}},
+			pvName:   "pvc-0002",
+			expectID: 1,
+		},
+		"expired reservation": {
+			reserved: map[string]quotaProjectReservation{"pvc-0001": {id: 1, expires: now.Add(-time.Second)}},
+			pvName:   "pvc-0002",
+			expectID: 1,
+		},
+		"in use on the node": {
+			pvIDs:    map[string]uint32{"pvc-0001": 1},
+			inUse:    []uint32{2, 3},
+			pvName:   "pvc-0002",
+			expectID: 4,
+		},
+		"reserved ID in use on the node": {
+			reserved: map[string]quotaProjectReservation{"pvc-0002": {id: 1, expires: now.Add(time.Hour)}},
+			inUse:    []uint32{1},
+			pvName:   "pvc-0002",
+			expectID: 2,
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			a := newQuotaProjectAllocator()
+			if v.reserved != nil {
+				a.reserved["node-1"] = v.reserved
+			}
+			a.markInUse("node-1", v.inUse)
+			id, err := a.allocate("node-1", v.pvName, v.pvIDs, now)
+			if err != nil {
+				t.Fatalf("expected error to be nil, but got %v", err)
+			}
+			if id != v.expectID {
+				t.Errorf("expected project ID %v, but got %v", v.expectID, id)
+			}
+		})
+	}
+}
+
+func TestQuotaProjectReserve(t *testing.T) {
+	now := time.Now()
+	a := newQuotaProjectAllocator()
+	a.reserve("node-1", "pvc-0001", 1, now)
+
+	if id, _ := a.allocate("node-1", "pvc-0002", nil, now); id != 2 {
+		t.Errorf("expected project ID 2 on node-1, but got %v", id)
+	}
+	if id, _ := a.allocate("node-2", "pvc-0002", nil, now); id != 1 {
+		t.Errorf("expected project ID 1 on node-2, but got %v", id)
+	}
+	if id, _ := a.allocate("node-1", "pvc-0002", nil, now.Add(quotaProjectReservationTTL+time.Second)); id != 1 {
+		t.Errorf("expected project ID 1 after the reservation expired, but got %v", id)
+	}
+}
+
+func TestReserveQuotaProject(t *testing.T) {
+	p := &Provisioner{
+		kubeClient:    fake.NewSimpleClientset(),
+		quotaProjects: newQuotaProjectAllocator(),
+	}
+	nodeAffinityLabels := map[string]string{k8sNodeLabelKeyHostname: "node-1"}
+	node := quotaProjectNode(nodeAffinityLabels)
+
+	// The quota of the volumes is applied without the node locked, so
+	// the project IDs reserved for them must be distinct.
+	first, err := p.reserveQuotaProject(context.Background(), node, &HelperPodOptions{name: "pvc-0001", nodeAffinityLabels: nodeAffinityLabels})
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	second, err := p.reserveQuotaProject(context.Background(), node, &HelperPodOptions{name: "pvc-0002", nodeAffinityLabels: nodeAffinityLabels})
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	if first == second {
+		t.Errorf("expected distinct project IDs, but got %v for both volumes", first)
+	}
+	again, err := p.reserveQuotaProject(context.Background(), node, &HelperPodOptions{name: "pvc-0001", nodeAffinityLabels: nodeAffinityLabels})
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	if again != first {
+		t.Errorf("expected project ID %v reserved for the volume, but got %v", first, again)
+	}
+}
+
+func TestGetQuotaProjectIDs(t *testing.T) {
+	newPV := func(name, node, id string) *v1.PersistentVolume {
+		pv := &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{string(mconfig.CASTypeKey): "local-hostpath"},
+			},
+		}
+		if id != "" {
+			pv.Annotations = map[string]string{quotaProjectIDAnnotation: id}
+		}
+		pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+			Required: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{
+					MatchExpressions: []v1.NodeSelectorRequirement{
+						{Key: k8sNodeLabelKeyHostname, Operator: v1.NodeSelectorOpIn, Values: []string{node}},
+					},
+				}},
+			},
+		}
+		return pv
+	}
+	p := &Provisioner{
+		kubeClient: fake.NewSimpleClientset(
+			newPV("pvc-0001", "node-1", "1"),
+			newPV("pvc-0002", "node-1", "7"),
+			newPV("pvc-0003", "node-2", "2"),
+			newPV("pvc-0004", "node-1", ""),
+			newPV("pvc-0005", "node-1", "invalid"),
+		),
+	}
+
+	ids, err := p.getQuotaProjectIDs(context.Background(), map[string]string{k8sNodeLabelKeyHostname: "node-1"})
+	if err != nil {
+		t.Fatalf("expected error to be nil, but got %v", err)
+	}
+	expected := map[string]uint32{"pvc-0001": 1, "pvc-0002": 7}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected project IDs %v, but got %v", expected, ids)
+	}
+}
+
+func TestParseQuotaProjectID(t *testing.T) {
+	testCases := map[string]struct {
+		value     string
+		expectID  uint32
+		expectErr bool
+	}{
+		"valid":        {value: "42", expectID: 42},
+		"default":      {value: "0", expectErr: true},
+		"out of range": {value: "4294967295", expectErr: true},
+		"negative":     {value: "-1", expectErr: true},
+		"not a number": {value: "abc", expectErr: true},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			id, err := parseQuotaProjectID(v.value)
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, but got %v", v.expectErr, err)
+			}
+			if id != v.expectID {
+				t.Errorf("expected project ID %v, but got %v", v.expectID, id)
+			}
+		})
+	}
+}
+
+func TestGetQuotaProjectsInUse(t *testing.T) {
+	testCases := map[string]struct {
+		err         error
+		expectIDs   []uint32
+		expectInUse bool
+	}{
+		"in use": {
+			err: errors.Wrap(&helperPodError{
+				podName:  "quota-pvc-0001",
+				logs:     "quota project IDs in use: 1 2 5 \n",
+				exitCode: quotaProjectInUseExitCode,
+			}, "failed"),
+			expectIDs:   []uint32{1, 2, 5},
+			expectInUse: true,
+		},
+		"in use without logs": {
+			err:         &helperPodError{podName: "quota-pvc-0001", exitCode: quotaProjectInUseExitCode},
+			expectInUse: true,
+		},
+		"other helper pod failure": {
+			err: &helperPodError{podName: "quota-pvc-0001", logs: "setquota: Cannot set quota", exitCode: 1},
+		},
+		"message logged by another failure": {
+			err: &helperPodError{podName: "quota-pvc-0001", logs: "quota project IDs in use: 1", exitCode: 1},
+		},
+		"not a helper pod error": {
+			err: errors.New("quota project IDs in use: 1"),
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			ids, inUse := getQuotaProjectsInUse(v.err)
+			if inUse != v.expectInUse {
+				t.Fatalf("expected in use %v, but got %v", v.expectInUse, inUse)
+			}
+			if !reflect.DeepEqual(ids, v.expectIDs) {
+				t.Errorf("expected project IDs %v, but got %v", v.expectIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// seedAnnotation is set on the hostpath PV with the source with
+	// which the volume was populated.
+	seedAnnotation = "local.openebs.io/seed"
+)
+
+// volumeSeed is the source with which a new volume is populated. The
+// name is the name of the ConfigMap or Secret in the namespace of the
+// PVC, or the path of the tarball on the node.
+type volumeSeed struct {
+	kind string
+	name string
+}
+
+// String returns the seed as <kind>/<name>
+func (s *volumeSeed) String() string {
+	return s.kind + "/" + s.name
+}
+
+// seedSecretName returns the name of the Secret holding the copy of
+// the ConfigMap or Secret with which the volume is populated.
+func seedSecretName(pvName string) string {
+	return "seed-" + pvName
+}
+
+// createSeedSecret copies the data of the ConfigMap or Secret of the
+// seed, from the namespace of the PVC into a Secret in the namespace of
+// the helper jobs, so that it can be mounted by the init helper pod.
+// The data of a ConfigMap is also copied into a Secret, as the helper
+// pod does not need to tell them apart.
+func (p *Provisioner) createSeedSecret(ctx context.Context, pvName string, pvc *v1.PersistentVolumeClaim, seed *volumeSeed) (*v1.Secret, error) {
+	data := map[string][]byte{}
+	switch seed.kind {
+	case KeySeedConfigMap:
+		configMap, err := p.kubeClient.CoreV1().ConfigMaps(pvc.Namespace).Get(ctx, seed.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get seed configmap %v/%v", pvc.Namespace, seed.name)
+		}
+		for k, v := range configMap.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range configMap.BinaryData {
+			data[k] = v
+		}
+	case KeySeedSecret:
+		secret, err := p.kubeClient.CoreV1().Secrets(pvc.Namespace).Get(ctx, seed.name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get seed secret %v/%v", pvc.Namespace, seed.name)
+		}
+		for k, v := range secret.Data {
+			data[k] = v
+		}
+	default:
+		return nil, errors.Errorf("seed %v is not a configmap or secret", seed)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      seedSecretName(pvName),
+			Namespace: p.getHelperNamespace(),
+			Labels: map[string]string{
+				string(mconfig.CASTypeKey): "local-hostpath",
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+	created, err := p.kubeClient.CoreV1().Secrets(p.getHelperNamespace()).Create(ctx, secret, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		// Left behind by an earlier attempt to provision the volume.
+		created, err = p.kubeClient.CoreV1().Secrets(p.getHelperNamespace()).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create seed secret %v", secret.Name)
+	}
+	return created, nil
+}
+
+// deleteSeedSecret deletes the Secret created by createSeedSecret.
+func (p *Provisioner) deleteSeedSecret(ctx context.Context, pvName string) {
+	name := seedSecretName(pvName)
+	err := p.kubeClient.CoreV1().Secrets(p.getHelperNamespace()).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("unable to delete the seed secret %v: %v", name, err)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateSeedSecret(t *testing.T) {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvcName", Namespace: "app"},
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-skeleton", Namespace: "app"},
+		Data:       map[string]string{"app.yaml": "key: value"},
+		BinaryData: map[string][]byte{"logo.png": {0x89, 0x50}},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-credentials", Namespace: "app"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	staleSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "seed-pvName", Namespace: "openebs"},
+		Data:       map[string][]byte{"stale": []byte("stale")},
+	}
+
+	testCases := map[string]struct {
+		objects     []runtime.Object
+		seed        *volumeSeed
+		expectData  map[string][]byte
+		expectError bool
+	}{
+		"configmap": {
+			objects: []runtime.Object{configMap},
+			seed:    &volumeSeed{kind: KeySeedConfigMap, name: "app-skeleton"},
+			expectData: map[string][]byte{
+				"app.yaml": []byte("key: value"),
+				"logo.png": {0x89, 0x50},
+			},
+		},
+		"secret": {
+			objects:    []runtime.Object{secret, staleSecret},
+			seed:       &volumeSeed{kind: KeySeedSecret, name: "app-credentials"},
+			expectData: map[string][]byte{"password": []byte("secret")},
+		},
+		"configmap in another namespace": {
+			objects:     []runtime.Object{&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-skeleton", Namespace: "other"}}},
+			seed:        &volumeSeed{kind: KeySeedConfigMap, name: "app-skeleton"},
+			expectError: true,
+		},
+		"tarball": {
+			seed:        &volumeSeed{kind: KeySeedTarball, name: "/var/openebs/seeds/app.tar"},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p := &Provisioner{
+				kubeClient: fake.NewSimpleClientset(tc.objects...),
+				namespace:  "openebs",
+			}
+			created, err := p.createSeedSecret(context.TODO(), "pvName", pvc, tc.seed)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if created.Name != "seed-pvName" || created.Namespace != "openebs" {
+				t.Errorf("expected secret openebs/seed-pvName, got %v/%v", created.Namespace, created.Name)
+			}
+			if !reflect.DeepEqual(created.Data, tc.expectData) {
+				t.Errorf("expected data %v, got %v", tc.expectData, created.Data)
+			}
+
+			p.deleteSeedSecret(context.TODO(), "pvName")
+			_, err = p.kubeClient.CoreV1().Secrets("openebs").Get(context.TODO(), "seed-pvName", metav1.GetOptions{})
+			if err == nil {
+				t.Errorf("expected seed secret to be deleted")
+			}
+		})
+	}
+}
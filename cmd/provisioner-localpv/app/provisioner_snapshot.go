@@ -0,0 +1,229 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/openebs/maya/pkg/alertlog"
+	hostpath "github.com/openebs/maya/pkg/hostpath/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// snapshotPathAnnotation is set on the VolumeSnapshotContent with
+	// the directory of the snapshot on the node.
+	snapshotPathAnnotation = "local.openebs.io/snapshot-path"
+	// snapshotModeAnnotation is set on the VolumeSnapshotContent with
+	// the mode (copy or tar) in which the snapshot was taken.
+	snapshotModeAnnotation = "local.openebs.io/snapshot-mode"
+	// snapshotNodeAnnotation is set on the VolumeSnapshotContent with
+	// the json encoded affinity labels of the node of the snapshot.
+	snapshotNodeAnnotation = "local.openebs.io/snapshot-node-labels"
+	// snapshotSourceAnnotation is set on the VolumeSnapshotContent with
+	// the name of the snapshotted volume.
+	snapshotSourceAnnotation = "local.openebs.io/snapshot-source-volume"
+
+	// KeySnapshotRoot is the VolumeSnapshotClass parameter with the
+	// directory under which the snapshots are stored. Defaults to the
+	// .snapshots directory under the root path of the volume.
+	KeySnapshotRoot = "snapshotRoot"
+	// KeySnapshotMode is the VolumeSnapshotClass parameter with the
+	// mode in which the snapshots are taken. Either copy (default)
+	// or tar.
+	KeySnapshotMode = "snapshotMode"
+
+	defaultSnapshotDir = ".snapshots"
+)
+
+var (
+	volumeSnapshotGVR = schema.GroupVersionResource{
+		Group: SnapshotAPIGroup, Version: "v1", Resource: "volumesnapshots",
+	}
+	volumeSnapshotContentGVR = schema.GroupVersionResource{
+		Group: SnapshotAPIGroup, Version: "v1", Resource: "volumesnapshotcontents",
+	}
+	volumeSnapshotClassGVR = schema.GroupVersionResource{
+		Group: SnapshotAPIGroup, Version: "v1", Resource: "volumesnapshotclasses",
+	}
+)
+
+// getSnapshotDataSource returns the snapshot directory of the
+// VolumeSnapshot requested as the dataSource of the PVC.
+func (p *Provisioner) getSnapshotDataSource(ctx context.Context, pvc *v1.PersistentVolumeClaim) (*volumeDataSource, error) {
+	if p.dynamicClient == nil {
+		return nil, errors.Errorf("snapshot feature not supported by this provisioner")
+	}
+
+	snapName := pvc.Spec.DataSource.Name
+	snapshot, err := p.dynamicClient.Resource(volumeSnapshotGVR).Namespace(pvc.Namespace).Get(ctx, snapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot %v/%v", pvc.Namespace, snapName)
+	}
+	if snapshot.GetDeletionTimestamp() != nil {
+		return nil, errors.Errorf("snapshot %v/%v is being deleted", pvc.Namespace, snapName)
+	}
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	contentName, _, _ := unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+	if !ready || contentName == "" {
+		return nil, errors.Errorf("snapshot %v/%v is not ready to use", pvc.Namespace, snapName)
+	}
+
+	content, err := p.dynamicClient.Resource(volumeSnapshotContentGVR).Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot content %v", contentName)
+	}
+	return newSnapshotDataSource(content)
+}
+
+// newSnapshotDataSource returns the data source recorded on a
+// VolumeSnapshotContent created by this provisioner.
+func newSnapshotDataSource(content *unstructured.Unstructured) (*volumeDataSource, error) {
+	driver, _, _ := unstructured.NestedString(content.Object, "spec", "driver")
+	if driver != provisionerName {
+		return nil, errors.Errorf("snapshot content %v is not a hostpath Local PV snapshot", content.GetName())
+	}
+
+	annotations := content.GetAnnotations()
+	source := &volumeDataSource{
+		name: content.GetName(),
+		path: annotations[snapshotPathAnnotation],
+		mode: annotations[snapshotModeAnnotation],
+	}
+	if source.path == "" {
+		return nil, errors.Errorf("snapshot content %v has no snapshot path", content.GetName())
+	}
+	if err := json.Unmarshal([]byte(annotations[snapshotNodeAnnotation]), &source.nodeAffinityLabels); err != nil {
+		return nil, errors.Wrapf(err, "invalid node labels on snapshot content %v", content.GetName())
+	}
+
+	restoreSize, _, _ := unstructured.NestedInt64(content.Object, "status", "restoreSize")
+	source.size = *resource.NewQuantity(restoreSize, resource.BinarySI)
+	return source, nil
+}
+
+// getSnapshotRoot returns the directory under which the snapshots of
+// the volume are stored, as per the VolumeSnapshotClass parameters.
+func getSnapshotRoot(pv *v1.PersistentVolume, parameters map[string]string) (string, error) {
+	snapshotRoot := parameters[KeySnapshotRoot]
+	if snapshotRoot == "" {
+		rootPath := pv.Annotations[rootPathAnnotation]
+		if rootPath == "" {
+			rootPath = filepath.Dir(persistentvolume.NewForAPIObject(pv).GetPath())
+		}
+		snapshotRoot = filepath.Join(rootPath, defaultSnapshotDir)
+	}
+	if !filepath.IsAbs(snapshotRoot) || !validPathRegex.MatchString(snapshotRoot) {
+		return "", errors.Errorf("invalid snapshot root %q", snapshotRoot)
+	}
+	snapshotRoot = filepath.Clean(snapshotRoot)
+
+	_, err := hostpath.NewBuilder().WithPath(snapshotRoot).
+		WithCheckf(hostpath.IsNonRoot(), "snapshot root {%v} should not be the root directory", snapshotRoot).
+		ValidateAndBuild()
+	if err != nil {
+		return "", err
+	}
+	return snapshotRoot, nil
+}
+
+// takeSnapshot copies the data of the hostpath PV into the snapshot
+// directory under snapshotRoot.
+func (p *Provisioner) takeSnapshot(ctx context.Context, pv *v1.PersistentVolume, snapshotRoot, snapshotDir, mode string) error {
+	pvObj := persistentvolume.NewForAPIObject(pv)
+	nodeAffinityLabels := pvObj.GetAffinitedNodeLabels()
+	if len(nodeAffinityLabels) == 0 {
+		return errors.Errorf("cannot find affinited node details of volume %v", pv.Name)
+	}
+	nodeObject, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := filepath.Join(snapshotRoot, snapshotDir)
+	klog.Infof("Creating snapshot %v of volume %v at %v", snapshotPath, pv.Name, GetNodeHostname(nodeObject))
+	podOpts := &HelperPodOptions{
+		name:               snapshotDir,
+		eventObject:        pv,
+		path:               snapshotPath,
+		rootPath:           snapshotRoot,
+		sourcePath:         pvObj.GetPath(),
+		sourceMode:         mode,
+		nodeAffinityLabels: nodeAffinityLabels,
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(nodeObject),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+	}
+	if err := p.createSnapshotPod(ctx, podOpts); err != nil {
+		alertlog.Logger.Errorw("",
+			"eventcode", eventCodeSnapshotFailure,
+			"msg", "Failed to create snapshot of Local PV",
+			"rname", pv.Name,
+			"reason", err.Error(),
+		)
+		p.recordEvent(pv, v1.EventTypeWarning, eventCodeSnapshotFailure, "Failed to create snapshot of Local PV: %v", err)
+		countFailure(eventCodeSnapshotFailure, "Snapshot creation failed")
+		return errors.Wrapf(err, "failed to create snapshot of volume %v", pv.Name)
+	}
+	alertlog.Logger.Infow("",
+		"eventcode", eventCodeSnapshotSuccess,
+		"msg", "Successfully created snapshot of Local PV",
+		"rname", pv.Name,
+		"snapshot", snapshotDir,
+	)
+	p.recordEvent(pv, v1.EventTypeNormal, eventCodeSnapshotSuccess, "Successfully created snapshot of Local PV")
+	return nil
+}
+
+// deleteSnapshot removes the snapshot directory recorded on the
+// VolumeSnapshotContent from the node.
+func (p *Provisioner) deleteSnapshot(ctx context.Context, content *unstructured.Unstructured) error {
+	source, err := newSnapshotDataSource(content)
+	if err != nil {
+		return err
+	}
+	nodeObject, err := p.GetNodeObjectFromLabels(source.nodeAffinityLabels)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Deleting snapshot %v at %v:%v", content.GetName(), GetNodeHostname(nodeObject), source.path)
+	podOpts := &HelperPodOptions{
+		cmdsForPath:        []string{"rm", "-rf"},
+		name:               content.GetName(),
+		path:               source.path,
+		rootPath:           filepath.Dir(source.path),
+		nodeAffinityLabels: source.nodeAffinityLabels,
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(nodeObject),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+	}
+	if err := p.createCleanupPod(ctx, podOpts); err != nil {
+		return errors.Wrapf(err, "failed to delete snapshot %v", content.GetName())
+	}
+	return nil
+}
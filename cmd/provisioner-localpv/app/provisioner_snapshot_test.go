@@ -0,0 +1,87 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSnapshotRoot(t *testing.T) {
+	fakePV := func(rootPath string) *v1.PersistentVolume {
+		pv := &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pvName",
+				Annotations: map[string]string{},
+			},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					Local: &v1.LocalVolumeSource{Path: "/var/openebs/local/ns/pvName"},
+				},
+			},
+		}
+		if rootPath != "" {
+			pv.Annotations[rootPathAnnotation] = rootPath
+		}
+		return pv
+	}
+
+	testCases := map[string]struct {
+		pv         *v1.PersistentVolume
+		parameters map[string]string
+		expectRoot string
+		expectErr  bool
+	}{
+		"default under root path": {
+			pv:         fakePV("/var/openebs/local"),
+			expectRoot: "/var/openebs/local/.snapshots",
+		},
+		"default under parent directory": {
+			pv:         fakePV(""),
+			expectRoot: "/var/openebs/local/ns/.snapshots",
+		},
+		"snapshot root parameter": {
+			pv:         fakePV("/var/openebs/local"),
+			parameters: map[string]string{KeySnapshotRoot: "/mnt/snapshots/"},
+			expectRoot: "/mnt/snapshots",
+		},
+		"relative snapshot root": {
+			pv:         fakePV("/var/openebs/local"),
+			parameters: map[string]string{KeySnapshotRoot: "snapshots"},
+			expectErr:  true,
+		},
+		"root directory": {
+			pv:         fakePV("/var/openebs/local"),
+			parameters: map[string]string{KeySnapshotRoot: "/"},
+			expectErr:  true,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			root, err := getSnapshotRoot(v.pv, v.parameters)
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %t got %v", v.expectErr, err)
+			}
+			if root != v.expectRoot {
+				t.Errorf("expected %q got %q", v.expectRoot, root)
+			}
+		})
+	}
+}
@@ -44,7 +44,7 @@ func TestValidateVolumeSource(t *testing.T) {
 					},
 				},
 			},
-			iserr: true,
+			iserr: false,
 		},
 		"clone volume with no name": {
 			pvc: v1.PersistentVolumeClaim{
@@ -68,7 +68,7 @@ func TestValidateVolumeSource(t *testing.T) {
 					},
 				},
 			},
-			iserr: true,
+			iserr: false,
 		},
 		"snapshot volume with no name": {
 			pvc: v1.PersistentVolumeClaim{
@@ -0,0 +1,349 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// trashDir is the directory under the BasePath into which the
+	// directories of the deleted volumes are moved.
+	trashDir = ".trash"
+
+	// trashLabel is set on the ConfigMaps which record the trashed
+	// volumes.
+	trashLabel = "local.openebs.io/trash"
+
+	// The keys of the trash record ConfigMap
+	trashRecordPV           = "pv"
+	trashRecordRoot         = "root"
+	trashRecordEntry        = "entry"
+	trashRecordTrashedAt    = "trashedAt"
+	trashRecordRetention    = "retention"
+	trashRecordMinAvailable = "minAvailable"
+)
+
+// trashPolicy is the trash mode configured in the StorageClass.
+type trashPolicy struct {
+	retention    time.Duration
+	minAvailable string
+}
+
+// trashRecord records a trashed volume. It is stored as a ConfigMap in
+// the namespace of the provisioner, named trash-<PV name>. The trashed
+// directory is <root>/.trash/<entry> on the node of the volume.
+type trashRecord struct {
+	pv           *v1.PersistentVolume
+	root         string
+	entry        string
+	trashedAt    time.Time
+	retention    time.Duration
+	minAvailable string
+}
+
+// trashRecordName returns the name of the ConfigMap of the trashed PV.
+func trashRecordName(pvName string) string {
+	return "trash-" + pvName
+}
+
+// trashEntry returns the name of the trashed directory of the PV. The
+// time is in Unix seconds, so that the entry is a valid object name.
+func trashEntry(pvName string, trashedAt time.Time) string {
+	return pvName + "-" + strconv.FormatInt(trashedAt.Unix(), 10)
+}
+
+// trashPurgeName returns the name of the purge helper job of the trashed
+// directory. The entry is hashed, so that the name of the job is a valid
+// label value whatever the length of the PV name.
+func trashPurgeName(entry string) string {
+	return "purge-" + shortHash(entry)
+}
+
+// newTrashRecord parses the trash record ConfigMap.
+func newTrashRecord(configMap *v1.ConfigMap) (*trashRecord, error) {
+	pv := &v1.PersistentVolume{}
+	if err := json.Unmarshal([]byte(configMap.Data[trashRecordPV]), pv); err != nil {
+		return nil, errors.Wrapf(err, "invalid pv in trash record %v", configMap.Name)
+	}
+	trashedAt, err := time.Parse(time.RFC3339, configMap.Data[trashRecordTrashedAt])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid trashedAt in trash record %v", configMap.Name)
+	}
+	retention, err := time.ParseDuration(configMap.Data[trashRecordRetention])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid retention in trash record %v", configMap.Name)
+	}
+	record := &trashRecord{
+		pv:           pv,
+		root:         configMap.Data[trashRecordRoot],
+		entry:        configMap.Data[trashRecordEntry],
+		trashedAt:    trashedAt,
+		retention:    retention,
+		minAvailable: configMap.Data[trashRecordMinAvailable],
+	}
+	if record.root == "" || record.entry == "" || strings.Contains(record.entry, "/") {
+		return nil, errors.Errorf("invalid path in trash record %v", configMap.Name)
+	}
+	return record, nil
+}
+
+// configMap returns the trash record as a ConfigMap.
+func (r *trashRecord) configMap(namespace string) (*v1.ConfigMap, error) {
+	pv, err := json.Marshal(r.pv)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      trashRecordName(r.pv.Name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				string(mconfig.CASTypeKey): "local-hostpath",
+				trashLabel:                 "true",
+			},
+		},
+		Data: map[string]string{
+			trashRecordPV:           string(pv),
+			trashRecordRoot:         r.root,
+			trashRecordEntry:        r.entry,
+			trashRecordTrashedAt:    r.trashedAt.UTC().Format(time.RFC3339),
+			trashRecordRetention:    r.retention.String(),
+			trashRecordMinAvailable: r.minAvailable,
+		},
+	}, nil
+}
+
+// trashPath returns the path of the trashed directory on the node.
+func (r *trashRecord) trashPath() string {
+	return filepath.Join(r.root, trashDir, r.entry)
+}
+
+// isExpired returns true if the retention period of the trashed
+// volume is over.
+func (r *trashRecord) isExpired(now time.Time) bool {
+	return !now.Before(r.trashedAt.Add(r.retention))
+}
+
+// parseMinAvailable parses the minAvailable of the trash policy, which
+// is either a percentage of the filesystem size or a quantity.
+func parseMinAvailable(minAvailable string) (float64, int64, error) {
+	if strings.HasSuffix(minAvailable, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(minAvailable, "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return 0, 0, errors.Errorf("invalid trash minAvailable {%v}", minAvailable)
+		}
+		return percent, 0, nil
+	}
+	quantity, err := resource.ParseQuantity(minAvailable)
+	if err != nil || quantity.Sign() < 0 {
+		return 0, 0, errors.Errorf("invalid trash minAvailable {%v}", minAvailable)
+	}
+	return 0, quantity.Value(), nil
+}
+
+// isBelowMinAvailable returns true if the available bytes of the
+// filesystem are below the minAvailable of the trash policy.
+func isBelowMinAvailable(minAvailable string, used, available int64) bool {
+	percent, bytes, err := parseMinAvailable(minAvailable)
+	if err != nil {
+		return false
+	}
+	if bytes != 0 {
+		return available < bytes
+	}
+	return float64(available)*100 < percent*float64(used+available)
+}
+
+// getTrashPolicy returns the trash policy of the StorageClass of the
+// PV. No policy is returned if the StorageClass no longer exists.
+func (p *Provisioner) getTrashPolicy(ctx context.Context, pv *v1.PersistentVolume) (*trashPolicy, error) {
+	volumeConfig, err := p.getStorageClassConfig(ctx, pv)
+	if err != nil || volumeConfig == nil {
+		return nil, err
+	}
+	return volumeConfig.GetTrashPolicy()
+}
+
+// getTrashRecord returns the trash record of the PV.
+func (p *Provisioner) getTrashRecord(ctx context.Context, pvName string) (*trashRecord, error) {
+	configMap, err := p.kubeClient.CoreV1().ConfigMaps(p.namespace).Get(ctx, trashRecordName(pvName), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return newTrashRecord(configMap)
+}
+
+// trashHostPath moves the directory of the volume into the trash
+// directory of its root path, and records the trashed volume. The
+// record is created first, and reused if the move is retried.
+func (p *Provisioner) trashHostPath(ctx context.Context, pv *v1.PersistentVolume, policy *trashPolicy, podOpts *HelperPodOptions) error {
+	record, err := p.getTrashRecord(ctx, pv.Name)
+	if k8serrors.IsNotFound(err) {
+		root, _, err := podOpts.extractSubPath()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		record = &trashRecord{
+			pv:           newTrashedPV(pv),
+			root:         root,
+			entry:        trashEntry(pv.Name, now),
+			trashedAt:    now,
+			retention:    policy.retention,
+			minAvailable: policy.minAvailable,
+		}
+		configMap, err := record.configMap(p.namespace)
+		if err != nil {
+			return err
+		}
+		_, err = p.kubeClient.CoreV1().ConfigMaps(p.namespace).Create(ctx, configMap, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to create trash record of volume %v", pv.Name)
+		}
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get trash record of volume %v", pv.Name)
+	}
+
+	klog.Infof("Moving volume %v to trash %v", pv.Name, record.trashPath())
+	podOpts.rootPath = record.root
+	if err := p.createTrashPod(ctx, podOpts, record.entry); err != nil {
+		return errors.Wrapf(err, "failed to move volume %v to trash", pv.Name)
+	}
+	return nil
+}
+
+// newTrashedPV returns a copy of the PV that can be created again when
+// the volume is restored.
+func newTrashedPV(pv *v1.PersistentVolume) *v1.PersistentVolume {
+	trashedPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pv.Name,
+			Labels:      pv.Labels,
+			Annotations: pv.Annotations,
+		},
+		Spec: *pv.Spec.DeepCopy(),
+	}
+	trashedPV.Spec.ClaimRef = nil
+	return trashedPV
+}
+
+// purgeTrash removes the trashed directory of the volume from its node,
+// and deletes the trash record.
+func (p *Provisioner) purgeTrash(ctx context.Context, record *trashRecord) error {
+	nodeAffinityLabels := persistentvolume.NewForAPIObject(record.pv).GetAffinitedNodeLabels()
+	node, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Purging trash %v of volume %v", record.trashPath(), record.pv.Name)
+	podOpts := &HelperPodOptions{
+		cmdsForPath:        []string{"rm", "-rf"},
+		name:               trashPurgeName(record.entry),
+		pvName:             record.pv.Name,
+		path:               record.trashPath(),
+		rootPath:           record.root,
+		nodeAffinityLabels: nodeAffinityLabels,
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(node),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+		wipePolicy:         getWipePolicy(record.pv),
+	}
+	if err := p.cleanupHostPath(ctx, record.pv, podOpts); err != nil {
+		return errors.Wrapf(err, "failed to purge trash of volume %v", record.pv.Name)
+	}
+
+	err = p.kubeClient.CoreV1().ConfigMaps(p.namespace).Delete(ctx, trashRecordName(record.pv.Name), metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete trash record of volume %v", record.pv.Name)
+	}
+	return nil
+}
+
+// RestoreTrash moves the trashed directory of the PV back to its path,
+// and creates the PV again. The PV is bound to the claim, if a claim
+// name is given.
+func (p *Provisioner) RestoreTrash(ctx context.Context, pvName, claimNamespace, claimName string) (*v1.PersistentVolume, error) {
+	record, err := p.getTrashRecord(ctx, pvName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get trash record of volume %v", pvName)
+	}
+	_, err = p.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+	if err == nil {
+		return nil, errors.Errorf("volume %v already exists", pvName)
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	pvObj := persistentvolume.NewForAPIObject(record.pv)
+	nodeAffinityLabels := pvObj.GetAffinitedNodeLabels()
+	node, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	klog.Infof("Restoring volume %v from trash %v", pvName, record.trashPath())
+	podOpts := &HelperPodOptions{
+		name:               pvName,
+		pvName:             pvName,
+		path:               pvObj.GetPath(),
+		rootPath:           record.root,
+		nodeAffinityLabels: nodeAffinityLabels,
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(node),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+	}
+	if err := p.createRestorePod(ctx, podOpts, record.entry); err != nil {
+		return nil, errors.Wrapf(err, "failed to restore volume %v from trash", pvName)
+	}
+
+	pv := record.pv.DeepCopy()
+	if claimName != "" {
+		pv.Spec.ClaimRef = &v1.ObjectReference{
+			Kind:      "PersistentVolumeClaim",
+			Namespace: claimNamespace,
+			Name:      claimName,
+		}
+	}
+	pv, err = p.kubeClient.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create volume %v", pvName)
+	}
+
+	err = p.kubeClient.CoreV1().ConfigMaps(p.namespace).Delete(ctx, trashRecordName(pvName), metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		klog.Errorf("Failed to delete trash record of volume %v: %v", pvName, err)
+	}
+	return pv, nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	menv "github.com/openebs/maya/pkg/env/v1alpha1"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTrashRecord(t *testing.T) {
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pvName",
+			ResourceVersion: "42",
+			Annotations:     map[string]string{rootPathAnnotation: "/var/openebs/local"},
+		},
+		Spec: v1.PersistentVolumeSpec{
+			StorageClassName: "openebs-hostpath",
+			ClaimRef:         &v1.ObjectReference{Namespace: "app", Name: "pvcName"},
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				Local: &v1.LocalVolumeSource{Path: "/var/openebs/local/pvName"},
+			},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeReleased},
+	}
+	trashedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := &trashRecord{
+		pv:           newTrashedPV(pv),
+		root:         "/var/openebs/local",
+		entry:        trashEntry("pvName", trashedAt),
+		trashedAt:    trashedAt,
+		retention:    72 * time.Hour,
+		minAvailable: "10%",
+	}
+
+	configMap, err := record.configMap("openebs")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if configMap.Name != "trash-pvName" || configMap.Labels[trashLabel] != "true" {
+		t.Errorf("unexpected trash record %v with labels %v", configMap.Name, configMap.Labels)
+	}
+
+	parsed, err := newTrashRecord(configMap)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(parsed, record) {
+		t.Errorf("expected record %+v, got %+v", record, parsed)
+	}
+	if parsed.pv.Spec.ClaimRef != nil || parsed.pv.ResourceVersion != "" || parsed.pv.Status.Phase != "" {
+		t.Errorf("expected trashed pv without claim, version and status, got %+v", parsed.pv)
+	}
+	if parsed.trashPath() != "/var/openebs/local/.trash/pvName-1767323045" {
+		t.Errorf("unexpected trash path %v", parsed.trashPath())
+	}
+	if parsed.isExpired(trashedAt.Add(71*time.Hour)) || !parsed.isExpired(trashedAt.Add(72*time.Hour)) {
+		t.Errorf("expected record to expire after 72h")
+	}
+
+	configMap.Data[trashRecordEntry] = "../../etc"
+	if _, err := newTrashRecord(configMap); err == nil {
+		t.Errorf("expected error for entry with a path")
+	}
+}
+
+func TestIsBelowMinAvailable(t *testing.T) {
+	testCases := map[string]struct {
+		minAvailable string
+		used         int64
+		available    int64
+		expectBelow  bool
+	}{
+		"percent above":  {minAvailable: "10%", used: 80, available: 20},
+		"percent below":  {minAvailable: "10%", used: 95, available: 5, expectBelow: true},
+		"quantity above": {minAvailable: "1Ki", used: 0, available: 1024},
+		"quantity below": {minAvailable: "1Ki", used: 0, available: 1023, expectBelow: true},
+		"invalid":        {minAvailable: "ten", used: 100, available: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			below := isBelowMinAvailable(tc.minAvailable, tc.used, tc.available)
+			if below != tc.expectBelow {
+				t.Errorf("expected %v, got %v", tc.expectBelow, below)
+			}
+		})
+	}
+}
+
+func TestGetTrashPolicyOfPV(t *testing.T) {
+	sc := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "openebs-hostpath",
+			Annotations: map[string]string{
+				string(mconfig.CASConfigKey): `
+- name: StorageType
+  value: "hostpath"
+- name: Trash
+  enabled: "true"
+  data:
+    retention: "24h"
+`,
+			},
+		},
+		Provisioner: provisionerName,
+	}
+
+	testCases := map[string]struct {
+		objects     []runtime.Object
+		scName      string
+		expectTrash *trashPolicy
+	}{
+		"trash enabled": {
+			objects:     []runtime.Object{sc},
+			scName:      "openebs-hostpath",
+			expectTrash: &trashPolicy{retention: 24 * time.Hour},
+		},
+		"storageclass not found": {
+			scName: "openebs-hostpath",
+		},
+		"no storageclass": {},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			p := &Provisioner{kubeClient: fake.NewSimpleClientset(tc.objects...)}
+			p.getVolumeConfig = p.GetVolumeConfig
+			pv := &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvName"},
+				Spec:       v1.PersistentVolumeSpec{StorageClassName: tc.scName},
+			}
+			trash, err := p.getTrashPolicy(context.TODO(), pv)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(trash, tc.expectTrash) {
+				t.Errorf("expected trash policy %v, got %v", tc.expectTrash, trash)
+			}
+		})
+	}
+}
+
+func TestPurgeTrashJobName(t *testing.T) {
+	os.Setenv(string(menv.OpenEBSServiceAccount), "openebs-maya-operator")
+	defer os.Unsetenv(string(menv.OpenEBSServiceAccount))
+
+	pvName := "pvc-0f0d2a6e-5a4c-4b0e-9a59-0123456789ab"
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{k8sNodeLabelKeyHostname: "node-1"},
+		},
+	}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				Local: &v1.LocalVolumeSource{Path: "/var/openebs/local/" + pvName},
+			},
+			NodeAffinity: &v1.VolumeNodeAffinity{
+				Required: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{Key: k8sNodeLabelKeyHostname, Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+	record := &trashRecord{
+		pv:    pv,
+		root:  "/var/openebs/local",
+		entry: trashEntry(pvName, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)),
+	}
+
+	client := fake.NewSimpleClientset(node)
+	var jobName string
+	client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		jobName = action.(k8stesting.CreateAction).GetObject().(*batchv1.Job).Name
+		return true, nil, errors.New("job not created")
+	})
+	p := &Provisioner{kubeClient: client, namespace: "openebs", helperImage: "openebs/linux-utils:ci"}
+	err := p.purgeTrash(context.Background(), record)
+	if jobName == "" {
+		t.Fatalf("expected the purge job to be created, got %v", err)
+	}
+	for _, name := range []string{record.entry, jobName} {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("expected %v to be a valid name, got %v", name, errs)
+		}
+	}
+	if errs := validation.IsValidLabelValue(jobName); len(errs) > 0 {
+		t.Errorf("expected job name %v to be a valid label value, got %v", jobName, errs)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+
+	"github.com/openebs/maya/pkg/alertlog"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// WipePolicyNone removes the files of the volume without
+	// overwriting them.
+	WipePolicyNone = "None"
+	// WipePolicyZeroFill overwrites the files, or the block device, of
+	// the volume with zeros.
+	WipePolicyZeroFill = "ZeroFill"
+	// WipePolicyShred overwrites the files, or the block device, of the
+	// volume with random data and then with zeros.
+	WipePolicyShred = "Shred"
+	// WipePolicyWipefs erases the filesystem signatures of the block
+	// device of the volume.
+	WipePolicyWipefs = "Wipefs"
+	// WipePolicyDiscard discards all the sectors of the block device of
+	// the volume.
+	WipePolicyDiscard = "Discard"
+
+	// wipePolicyAnnotation is set on the PV with the wipe policy applied
+	// when the volume is deleted.
+	wipePolicyAnnotation = "local.openebs.io/wipe-policy"
+)
+
+// validateWipePolicy returns an error if the wipe policy cannot be
+// applied to a volume of the StorageType and VolumeMode. Wipefs and
+// Discard only apply to the block device of a volume in Block mode.
+func validateWipePolicy(policy, stgType string, volumeMode v1.PersistentVolumeMode) error {
+	switch policy {
+	case WipePolicyNone, WipePolicyZeroFill, WipePolicyShred:
+		return nil
+	case WipePolicyWipefs, WipePolicyDiscard:
+		if stgType == "device" && volumeMode == v1.PersistentVolumeBlock {
+			return nil
+		}
+	}
+	return errors.Errorf("wipe policy %v is not supported with StorageType %v and VolumeMode %v",
+		policy, stgType, volumeMode)
+}
+
+// wipeFilesCmd returns the command to overwrite the files under the
+// path according to the wipe policy. The command prints the number of
+// files overwritten so far, which is reported as the progress of the
+// wipe. The command is skipped if the path does not exist.
+func wipeFilesCmd(policy, path string) string {
+	var shredCmd string
+	switch policy {
+	case WipePolicyZeroFill:
+		shredCmd = "shred -n 0 -z"
+	case WipePolicyShred:
+		shredCmd = "shred -z"
+	default:
+		return ""
+	}
+	return "if [ -d " + path + " ]; then set -o pipefail; " +
+		"total=$(find " + path + " -type f | wc -l); " +
+		"find " + path + " -type f -exec sh -c 'for f; do " + shredCmd + " -- \"$f\" || exit 1; echo; done' sh {} + | " +
+		"awk -v total=$total '{ print \"wiped \" NR \"/\" total \" files\"; fflush() }' || exit 1; fi; "
+}
+
+// wipeDeviceCmd returns the command to wipe the block device according
+// to the wipe policy.
+func wipeDeviceCmd(policy, devicePath string) string {
+	switch policy {
+	case WipePolicyZeroFill:
+		return "blkdiscard -z " + devicePath
+	case WipePolicyShred:
+		return "shred -n 1 -z " + devicePath
+	case WipePolicyWipefs:
+		return "wipefs -a " + devicePath
+	case WipePolicyDiscard:
+		return "blkdiscard " + devicePath
+	}
+	return ""
+}
+
+// getWipePolicy returns the wipe policy recorded on the PV, or an empty
+// policy if the data of the volume is not to be overwritten.
+func getWipePolicy(pv *v1.PersistentVolume) string {
+	policy := pv.Annotations[wipePolicyAnnotation]
+	if policy == WipePolicyNone {
+		return ""
+	}
+	return policy
+}
+
+// wipeVolume runs the wipe of the volume, and reports its start,
+// progress and result as events of the PV.
+func (p *Provisioner) wipeVolume(pv *v1.PersistentVolume, policy string, wipe func(progress func(string)) error) error {
+	p.recordEvent(pv, v1.EventTypeNormal, eventCodeWipeStarted, "Wiping volume with policy %v", policy)
+	err := wipe(func(progress string) {
+		p.recordEvent(pv, v1.EventTypeNormal, eventCodeWipeProgress, "%v", progress)
+	})
+	if err != nil {
+		alertlog.Logger.Errorw("",
+			"eventcode", eventCodeWipeFailure,
+			"msg", "Failed to wipe Local PV",
+			"rname", pv.Name,
+			"reason", "Volume wipe failed",
+			"wipepolicy", policy,
+		)
+		p.recordEvent(pv, v1.EventTypeWarning, eventCodeWipeFailure, "Failed to wipe volume: %v", err)
+		countFailure(eventCodeWipeFailure, "Volume wipe failed")
+		return err
+	}
+	alertlog.Logger.Infow("",
+		"eventcode", eventCodeWipeSuccess,
+		"msg", "Successfully wiped Local PV",
+		"rname", pv.Name,
+		"wipepolicy", policy,
+	)
+	p.recordEvent(pv, v1.EventTypeNormal, eventCodeWipeSuccess, "Wiped volume with policy %v", policy)
+	return nil
+}
+
+// cleanupHostPath launches the cleanup helper pod of the hostpath
+// volume. The files of the volume are overwritten before they are
+// removed, if the PV has a wipe policy.
+func (p *Provisioner) cleanupHostPath(ctx context.Context, pv *v1.PersistentVolume, podOpts *HelperPodOptions) error {
+	if podOpts.wipePolicy == "" {
+		return p.createCleanupPod(ctx, podOpts)
+	}
+	return p.wipeVolume(pv, podOpts.wipePolicy, func(progress func(string)) error {
+		podOpts.progress = progress
+		return p.createCleanupPod(ctx, podOpts)
+	})
+}
+
+// wipeBlockDevice launches the wipe helper pod of the device volume on
+// the node of the block device.
+func (p *Provisioner) wipeBlockDevice(ctx context.Context, pv *v1.PersistentVolume, wipePolicy string) error {
+	pvObj := persistentvolume.NewForAPIObject(pv)
+	nodeAffinityLabels := pvObj.GetAffinitedNodeLabels()
+	node, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if err != nil {
+		return err
+	}
+	volumeMode := v1.PersistentVolumeFilesystem
+	if pv.Spec.VolumeMode != nil {
+		volumeMode = *pv.Spec.VolumeMode
+	}
+
+	podOpts := &HelperPodOptions{
+		name:               pv.Name,
+		pvName:             pv.Name,
+		path:               pvObj.GetPath(),
+		nodeAffinityLabels: nodeAffinityLabels,
+		serviceAccountName: getOpenEBSServiceAccountName(),
+		selectedNodeTaints: GetTaints(node),
+		imagePullSecrets:   GetImagePullSecrets(getOpenEBSImagePullSecrets()),
+		wipePolicy:         wipePolicy,
+	}
+	return p.wipeVolume(pv, wipePolicy, func(progress func(string)) error {
+		podOpts.progress = progress
+		return p.createWipeDevicePod(ctx, podOpts, volumeMode)
+	})
+}
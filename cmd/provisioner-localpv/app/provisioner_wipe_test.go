@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestValidateWipePolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy      string
+		stgType     string
+		volumeMode  v1.PersistentVolumeMode
+		expectError bool
+	}{
+		"hostpath zerofill":          {policy: WipePolicyZeroFill, stgType: "hostpath", volumeMode: v1.PersistentVolumeFilesystem},
+		"hostpath shred":             {policy: WipePolicyShred, stgType: "hostpath", volumeMode: v1.PersistentVolumeFilesystem},
+		"hostpath wipefs":            {policy: WipePolicyWipefs, stgType: "hostpath", volumeMode: v1.PersistentVolumeFilesystem, expectError: true},
+		"device block wipefs":        {policy: WipePolicyWipefs, stgType: "device", volumeMode: v1.PersistentVolumeBlock},
+		"device block discard":       {policy: WipePolicyDiscard, stgType: "device", volumeMode: v1.PersistentVolumeBlock},
+		"device filesystem discard":  {policy: WipePolicyDiscard, stgType: "device", volumeMode: v1.PersistentVolumeFilesystem, expectError: true},
+		"device filesystem zerofill": {policy: WipePolicyZeroFill, stgType: "device", volumeMode: v1.PersistentVolumeFilesystem},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := validateWipePolicy(tc.policy, tc.stgType, tc.volumeMode)
+			if tc.expectError && err == nil {
+				t.Errorf("expected error, got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWipeCmd(t *testing.T) {
+	if cmd := wipeFilesCmd(WipePolicyNone, "/data/pvc-1"); cmd != "" {
+		t.Errorf("expected no command for policy None, got %q", cmd)
+	}
+	cmd := wipeFilesCmd(WipePolicyZeroFill, "/data/pvc-1")
+	for _, expect := range []string{"if [ -d /data/pvc-1 ]", "find /data/pvc-1 -type f", "shred -n 0 -z -- \"$f\"", "set -o pipefail"} {
+		if !strings.Contains(cmd, expect) {
+			t.Errorf("expected %q in command %q", expect, cmd)
+		}
+	}
+	if cmd := wipeFilesCmd(WipePolicyShred, "/data/pvc-1"); !strings.Contains(cmd, "shred -z -- \"$f\"") {
+		t.Errorf("expected shred with random passes in command %q", cmd)
+	}
+
+	devicePath := "/dev/disk/by-id/scsi-0QEMU_disk"
+	for policy, expect := range map[string]string{
+		WipePolicyZeroFill: "blkdiscard -z " + devicePath,
+		WipePolicyShred:    "shred -n 1 -z " + devicePath,
+		WipePolicyWipefs:   "wipefs -a " + devicePath,
+		WipePolicyDiscard:  "blkdiscard " + devicePath,
+		WipePolicyNone:     "",
+	} {
+		if cmd := wipeDeviceCmd(policy, devicePath); cmd != expect {
+			t.Errorf("expected %q for policy %v, got %q", expect, policy, cmd)
+		}
+	}
+}
+
+func TestWipeVolume(t *testing.T) {
+	pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvName"}}
+	testCases := map[string]struct {
+		wipeErr      error
+		expectEvents []string
+	}{
+		"completed": {
+			expectEvents: []string{
+				"Normal " + eventCodeWipeStarted + " Wiping volume with policy ZeroFill",
+				"Normal " + eventCodeWipeProgress + " wiped 1/2 files",
+				"Normal " + eventCodeWipeSuccess + " Wiped volume with policy ZeroFill",
+			},
+		},
+		"failed": {
+			wipeErr: errors.New("pod failed"),
+			expectEvents: []string{
+				"Normal " + eventCodeWipeStarted + " Wiping volume with policy ZeroFill",
+				"Normal " + eventCodeWipeProgress + " wiped 1/2 files",
+				"Warning " + eventCodeWipeFailure + " Failed to wipe volume: pod failed",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			p := &Provisioner{recorder: recorder}
+			err := p.wipeVolume(pv, WipePolicyZeroFill, func(progress func(string)) error {
+				progress("wiped 1/2 files")
+				return tc.wipeErr
+			})
+			if err != tc.wipeErr {
+				t.Errorf("expected error %v, got %v", tc.wipeErr, err)
+			}
+			close(recorder.Events)
+			events := []string{}
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if !reflect.DeepEqual(events, tc.expectEvents) {
+				t.Errorf("expected events %v, got %v", tc.expectEvents, events)
+			}
+		})
+	}
+}
+
+func TestGetWipePolicyOfPV(t *testing.T) {
+	for annotation, expect := range map[string]string{
+		"":               "",
+		WipePolicyNone:   "",
+		WipePolicyShred:  WipePolicyShred,
+		WipePolicyWipefs: WipePolicyWipefs,
+	} {
+		pv := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{wipePolicyAnnotation: annotation},
+		}}
+		if policy := getWipePolicy(pv); policy != expect {
+			t.Errorf("expected wipe policy %q for annotation %q, got %q", expect, annotation, policy)
+		}
+	}
+}
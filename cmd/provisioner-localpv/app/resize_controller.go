@@ -0,0 +1,236 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+This file contains the controller used to expand Local PVs.
+
+The sig-storage-lib-external-provisioner library does not provide any
+hooks for volume expansion, and the in-tree expand controller waits for
+an external controller to resize volumes that are not CSI volumes. The
+ResizeController watches the PVCs provisioned by this provisioner and
+expands the PV whenever the requested storage is more than the capacity
+reported in the PVC status.
+*/
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// annStorageProvisioner is set by the PV controller on the PVC with
+	// the name of the provisioner responsible for the volume.
+	annStorageProvisioner     = "volume.kubernetes.io/storage-provisioner"
+	annBetaStorageProvisioner = "volume.beta.kubernetes.io/storage-provisioner"
+
+	// annDynamicallyProvisioned is set on the PV with the name of the
+	// provisioner that created it.
+	annDynamicallyProvisioned = "pv.kubernetes.io/provisioned-by"
+)
+
+// ResizeController expands the Local PVs of the PVCs whose requested
+// storage has been increased.
+type ResizeController struct {
+	provisioner *Provisioner
+	pvcLister   corelisters.PersistentVolumeClaimLister
+	pvcSynced   cache.InformerSynced
+	queue       workqueue.RateLimitingInterface
+}
+
+// NewResizeController returns a ResizeController which is notified
+// of PVC changes via the given informer.
+func NewResizeController(p *Provisioner, pvcInformer coreinformers.PersistentVolumeClaimInformer) *ResizeController {
+	rc := &ResizeController{
+		provisioner: p,
+		pvcLister:   pvcInformer.Lister(),
+		pvcSynced:   pvcInformer.Informer().HasSynced,
+		queue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "resize"),
+	}
+
+	pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: rc.enqueue,
+		UpdateFunc: func(_, newObj interface{}) {
+			rc.enqueue(newObj)
+		},
+	})
+	return rc
+}
+
+// Run processes the queued PVCs till the context is cancelled.
+func (rc *ResizeController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer rc.queue.ShutDown()
+
+	klog.Info("Starting resize controller")
+	defer klog.Info("Shutting down resize controller")
+
+	if !cache.WaitForCacheSync(ctx.Done(), rc.pvcSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, rc.runWorker, time.Second)
+	}
+	<-ctx.Done()
+}
+
+func (rc *ResizeController) enqueue(obj interface{}) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok || !isResizeRequired(pvc) {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(pvc)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	rc.queue.Add(key)
+}
+
+func (rc *ResizeController) runWorker(ctx context.Context) {
+	for rc.processNextItem(ctx) {
+	}
+}
+
+func (rc *ResizeController) processNextItem(ctx context.Context) bool {
+	key, quit := rc.queue.Get()
+	if quit {
+		return false
+	}
+	defer rc.queue.Done(key)
+
+	err := rc.provisioner.trackOperation("resize "+key.(string), helperStallTimeout, func() error {
+		return rc.syncPVC(ctx, key.(string))
+	})
+	if err != nil {
+		klog.Errorf("Failed to resize PVC %v: %v", key, err)
+		rc.queue.AddRateLimited(key)
+		return true
+	}
+	rc.queue.Forget(key)
+	return true
+}
+
+// syncPVC expands the PV bound to the PVC and then updates the
+// capacity in the PVC status.
+func (rc *ResizeController) syncPVC(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pvc, err := rc.pvcLister.PersistentVolumeClaims(namespace).Get(name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isResizeRequired(pvc) {
+		return nil
+	}
+
+	kubeClient := rc.provisioner.kubeClient
+	pv, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get volume %v", pvc.Spec.VolumeName)
+	}
+	if pv.Annotations[annDynamicallyProvisioned] != provisionerName {
+		return nil
+	}
+	if pvType := GetLocalPVType(pv); pvType != "local-hostpath" {
+		klog.Infof("Skipping resize of volume %v: not supported for %v", pv.Name, pvType)
+		return nil
+	}
+
+	newSize := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	pvSize := pv.Spec.Capacity[v1.ResourceStorage]
+	if pvSize.Cmp(newSize) < 0 {
+		pv, err = rc.provisioner.ExpandHostPath(ctx, pv, pvc, newSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	return markPVCResizeFinished(ctx, rc.provisioner, pvc, pv.Spec.Capacity[v1.ResourceStorage])
+}
+
+// markPVCResizeFinished sets the new capacity in the PVC status and
+// clears the resize conditions. Hostpath volumes do not need a
+// filesystem resize on the node, so the resize is complete.
+func markPVCResizeFinished(ctx context.Context, p *Provisioner, pvc *v1.PersistentVolumeClaim, newSize resource.Quantity) error {
+	pvc = pvc.DeepCopy()
+	if pvc.Status.Capacity == nil {
+		pvc.Status.Capacity = v1.ResourceList{}
+	}
+	pvc.Status.Capacity[v1.ResourceStorage] = newSize
+
+	conditions := []v1.PersistentVolumeClaimCondition{}
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == v1.PersistentVolumeClaimResizing ||
+			condition.Type == v1.PersistentVolumeClaimFileSystemResizePending {
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+	pvc.Status.Conditions = conditions
+
+	_, err := p.kubeClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).UpdateStatus(ctx, pvc, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update status of pvc %v/%v", pvc.Namespace, pvc.Name)
+	}
+	klog.Infof("Resized PVC %v/%v to %v", pvc.Namespace, pvc.Name, newSize.String())
+	return nil
+}
+
+// isResizeRequired returns true if the PVC is bound to a volume of this
+// provisioner and the requested storage is more than the PVC capacity.
+func isResizeRequired(pvc *v1.PersistentVolumeClaim) bool {
+	if pvc.Status.Phase != v1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return false
+	}
+
+	provisioner, found := pvc.Annotations[annStorageProvisioner]
+	if !found {
+		provisioner = pvc.Annotations[annBetaStorageProvisioner]
+	}
+	if provisioner != provisionerName {
+		return false
+	}
+
+	requestSize, found := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	if !found {
+		return false
+	}
+	capacity, found := pvc.Status.Capacity[v1.ResourceStorage]
+	if !found {
+		return false
+	}
+	return requestSize.Cmp(capacity) > 0
+}
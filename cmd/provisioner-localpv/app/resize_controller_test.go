@@ -0,0 +1,317 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	menv "github.com/openebs/maya/pkg/env/v1alpha1"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+func fakeResizePVC(provisioner, request, capacity string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvcName",
+			Namespace: "default",
+			Annotations: map[string]string{
+				annStorageProvisioner: provisioner,
+			},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: "pvName",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse(request),
+				},
+			},
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: phase,
+			Capacity: v1.ResourceList{
+				v1.ResourceStorage: resource.MustParse(capacity),
+			},
+		},
+	}
+}
+
+func TestIsResizeRequired(t *testing.T) {
+	testCases := map[string]struct {
+		pvc         *v1.PersistentVolumeClaim
+		expectValue bool
+	}{
+		"request more than capacity": {
+			pvc:         fakeResizePVC(provisionerName, "2Gi", "1Gi", v1.ClaimBound),
+			expectValue: true,
+		},
+		"request equal to capacity": {
+			pvc:         fakeResizePVC(provisionerName, "1Gi", "1Gi", v1.ClaimBound),
+			expectValue: false,
+		},
+		"request less than capacity": {
+			pvc:         fakeResizePVC(provisionerName, "1Gi", "2Gi", v1.ClaimBound),
+			expectValue: false,
+		},
+		"pvc of another provisioner": {
+			pvc:         fakeResizePVC("example.io/other", "2Gi", "1Gi", v1.ClaimBound),
+			expectValue: false,
+		},
+		"pvc not bound": {
+			pvc:         fakeResizePVC(provisionerName, "2Gi", "1Gi", v1.ClaimPending),
+			expectValue: false,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			actualValue := isResizeRequired(v.pvc)
+			if actualValue != v.expectValue {
+				t.Errorf("expected %t got %t", v.expectValue, actualValue)
+			}
+		})
+	}
+}
+
+func TestSyncPVC(t *testing.T) {
+	os.Setenv(string(menv.OpenEBSServiceAccount), "openebs-maya-operator")
+	defer os.Unsetenv(string(menv.OpenEBSServiceAccount))
+
+	resizeSC := func(quota bool) *storagev1.StorageClass {
+		config := `
+- name: StorageType
+  value: "hostpath"
+`
+		if quota {
+			config += `- name: XFSQuota
+  enabled: "true"
+  data:
+    softLimitGrace: "0%"
+    hardLimitGrace: "0%"
+`
+		}
+		return &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "openebs-hostpath",
+				Annotations: map[string]string{string(mconfig.CASConfigKey): config},
+			},
+			Provisioner: provisionerName,
+		}
+	}
+	resizePV := func(casType string, quota bool) *v1.PersistentVolume {
+		annotations := map[string]string{annDynamicallyProvisioned: provisionerName}
+		if quota {
+			annotations[quotaProjectIDAnnotation] = "1"
+			annotations[quotaSoftLimitGraceAnnotation] = "0%"
+			annotations[quotaHardLimitGraceAnnotation] = "0%"
+		}
+		pv, err := persistentvolume.NewBuilder().
+			WithName("pvName").
+			WithLabels(map[string]string{string(mconfig.CASTypeKey): casType}).
+			WithAnnotations(annotations).
+			WithCapacityQty(resource.MustParse("1Gi")).
+			WithLocalHostDirectory("/var/openebs/local/pvName").
+			WithNodeAffinity(map[string]string{k8sNodeLabelKeyHostname: "node-1"}).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pv.Spec.StorageClassName = "openebs-hostpath"
+		return pv
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{k8sNodeLabelKeyHostname: "node-1"},
+		},
+	}
+
+	testCases := map[string]struct {
+		casType string
+		// pvQuota is true if quota was applied to the volume at
+		// provisioning time, and scQuota if quota is enabled on the
+		// StorageClass now.
+		pvQuota bool
+		scQuota bool
+		// failedJobs is the number of quota helper jobs which fail to be
+		// created, before the jobs complete.
+		failedJobs   int
+		syncs        int
+		expectErr    bool
+		expectJobs   int
+		expectSize   string
+		expectEvents []string
+	}{
+		"not a hostpath volume": {
+			casType:    "local-device",
+			syncs:      1,
+			expectSize: "1Gi",
+		},
+		"hostpath without quota": {
+			casType:      "local-hostpath",
+			syncs:        1,
+			expectSize:   "2Gi",
+			expectEvents: []string{"Normal " + eventCodeResizeSuccess},
+		},
+		"hostpath with quota": {
+			casType:      "local-hostpath",
+			pvQuota:      true,
+			scQuota:      true,
+			syncs:        1,
+			expectJobs:   1,
+			expectSize:   "2Gi",
+			expectEvents: []string{"Normal " + eventCodeResizeSuccess},
+		},
+		"quota enabled on the StorageClass after provisioning": {
+			casType:      "local-hostpath",
+			scQuota:      true,
+			syncs:        1,
+			expectSize:   "2Gi",
+			expectEvents: []string{"Normal " + eventCodeResizeSuccess},
+		},
+		"quota disabled on the StorageClass after provisioning": {
+			casType:      "local-hostpath",
+			pvQuota:      true,
+			syncs:        1,
+			expectJobs:   1,
+			expectSize:   "2Gi",
+			expectEvents: []string{"Normal " + eventCodeResizeSuccess},
+		},
+		"quota update failed": {
+			casType:      "local-hostpath",
+			pvQuota:      true,
+			scQuota:      true,
+			failedJobs:   1,
+			syncs:        1,
+			expectErr:    true,
+			expectJobs:   1,
+			expectSize:   "1Gi",
+			expectEvents: []string{"Warning " + eventCodeResizeFailure},
+		},
+		"retry after failure": {
+			casType:      "local-hostpath",
+			pvQuota:      true,
+			scQuota:      true,
+			failedJobs:   1,
+			syncs:        2,
+			expectJobs:   2,
+			expectSize:   "2Gi",
+			expectEvents: []string{"Warning " + eventCodeResizeFailure, "Normal " + eventCodeResizeSuccess},
+		},
+	}
+
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			pvc := fakeResizePVC(provisionerName, "2Gi", "1Gi", v1.ClaimBound)
+			scName := "openebs-hostpath"
+			pvc.Spec.StorageClassName = &scName
+			pvc.Status.Conditions = []v1.PersistentVolumeClaimCondition{
+				{Type: v1.PersistentVolumeClaimResizing, Status: v1.ConditionTrue},
+			}
+
+			client := fake.NewSimpleClientset(resizeSC(v.scQuota), resizePV(v.casType, v.pvQuota), pvc, node)
+			jobs := 0
+			client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				jobs++
+				if jobs <= v.failedJobs {
+					return true, nil, errors.New("job not created")
+				}
+				// The job is created as completed, as there is no job
+				// controller to run it.
+				job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+				job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}
+				return false, nil, nil
+			})
+			recorder := record.NewFakeRecorder(10)
+			p := &Provisioner{
+				kubeClient:  client,
+				namespace:   "openebs",
+				helperImage: "openebs/linux-utils:ci",
+				recorder:    recorder,
+			}
+			p.getVolumeConfig = p.GetVolumeConfig
+
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			pvcInformer := informerFactory.Core().V1().PersistentVolumeClaims()
+			rc := NewResizeController(p, pvcInformer)
+			if err := pvcInformer.Informer().GetIndexer().Add(pvc); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			for i := 0; i < v.syncs; i++ {
+				err = rc.syncPVC(context.Background(), "default/pvcName")
+			}
+			if v.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, but got %v", v.expectErr, err)
+			}
+			if jobs != v.expectJobs {
+				t.Errorf("expected %v quota helper jobs, but got %v", v.expectJobs, jobs)
+			}
+
+			expectSize := resource.MustParse(v.expectSize)
+			latestPV, _ := client.CoreV1().PersistentVolumes().Get(context.Background(), "pvName", metav1.GetOptions{})
+			if size := latestPV.Spec.Capacity[v1.ResourceStorage]; size.Cmp(expectSize) != 0 {
+				t.Errorf("expected pv capacity %v, but got %v", v.expectSize, size.String())
+			}
+			latestPVC, _ := client.CoreV1().PersistentVolumeClaims("default").Get(context.Background(), "pvcName", metav1.GetOptions{})
+			if size := latestPVC.Status.Capacity[v1.ResourceStorage]; size.Cmp(expectSize) != 0 {
+				t.Errorf("expected pvc capacity %v, but got %v", v.expectSize, size.String())
+			}
+			// The Resizing condition is cleared only once the PVC is
+			// resized.
+			resized := expectSize.Cmp(resource.MustParse("2Gi")) == 0
+			if resizing := len(latestPVC.Status.Conditions) > 0; resizing == resized {
+				t.Errorf("expected pvc resizing %v, but got conditions %+v", !resized, latestPVC.Status.Conditions)
+			}
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				for _, reason := range []string{eventCodeResizeSuccess, eventCodeResizeFailure} {
+					if strings.Contains(event, reason) {
+						events = append(events, event)
+					}
+				}
+			}
+			if len(events) != len(v.expectEvents) {
+				t.Fatalf("expected events %v, but got %v", v.expectEvents, events)
+			}
+			for i, event := range events {
+				if !strings.HasPrefix(event, v.expectEvents[i]) {
+					t.Errorf("expected event %q, but got %q", v.expectEvents[i], event)
+				}
+			}
+		})
+	}
+}
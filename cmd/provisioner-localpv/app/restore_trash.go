@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mKube "github.com/openebs/maya/pkg/kubernetes/client/v1alpha1"
+	"github.com/openebs/maya/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+// newRestoreTrashCommand returns the command which restores a trashed
+// hostpath volume. It is run in the provisioner pod, as the helper pod
+// is launched with the service account of the provisioner.
+func newRestoreTrashCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore-trash <pv-name> [<pvc-namespace>/<pvc-name>]",
+		Short: "Restore a trashed hostpath volume",
+		Long: `Move the directory of a trashed hostpath volume back to its
+			path and create the PV again. If a PVC is given, the PV is
+			bound to the PVC, else the PV is made Available.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(restoreTrash(args), util.Fatal)
+		},
+	}
+}
+
+func restoreTrash(args []string) error {
+	var claimNamespace, claimName string
+	if len(args) == 2 {
+		parts := strings.Split(args[1], "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.Errorf("invalid pvc {%v}, expected <pvc-namespace>/<pvc-name>", args[1])
+		}
+		claimNamespace, claimName = parts[0], parts[1]
+	}
+
+	kubeClient, err := mKube.New().Clientset()
+	if err != nil {
+		return errors.Wrap(err, "unable to get k8s client")
+	}
+	provisioner, err := NewProvisioner(kubeClient, nil)
+	if err != nil {
+		return err
+	}
+
+	pv, err := provisioner.RestoreTrash(context.TODO(), args[0], claimNamespace, claimName)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored volume %v at %v\n", pv.Name, persistentvolume.NewForAPIObject(pv).GetPath())
+	return nil
+}
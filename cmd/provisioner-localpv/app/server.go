@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// newServeMuxes returns the handlers of the metrics and health endpoints
+// by the address at which they are served. The endpoints share a server
+// if their addresses are the same, and are not served if their address
+// is empty.
+func newServeMuxes(metricsAddress, healthAddress string, health *healthChecker) map[string]*http.ServeMux {
+	muxes := map[string]*http.ServeMux{}
+	muxFor := func(address string) *http.ServeMux {
+		if _, ok := muxes[address]; !ok {
+			muxes[address] = http.NewServeMux()
+		}
+		return muxes[address]
+	}
+	if metricsAddress != "" {
+		muxFor(metricsAddress).Handle(metricsPath, metricsHandler())
+	}
+	if healthAddress != "" {
+		health.register(muxFor(healthAddress))
+	}
+	return muxes
+}
+
+// runHTTPServer serves the handler at the address until the context is
+// cancelled.
+func runHTTPServer(ctx context.Context, address string, handler http.Handler) {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	klog.Infof("Starting HTTP server at %v", address)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.Errorf("HTTP server at %v failed: %v", address, err)
+	}
+}
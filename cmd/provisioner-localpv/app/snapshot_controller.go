@@ -0,0 +1,511 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+This file contains the controller used to snapshot hostpath Local PVs.
+
+The snapshot.storage.k8s.io VolumeSnapshot, VolumeSnapshotContent and
+VolumeSnapshotClass objects are used as is, so that the snapshots can be
+managed with the same tools as the CSI snapshots. The SnapshotController
+handles the VolumeSnapshots whose VolumeSnapshotClass has the driver
+openebs.io/local. For every such VolumeSnapshot a VolumeSnapshotContent
+named snapcontent-<snapshot uid> is created, the data of the volume is
+copied to the snapshot directory on the node of the volume by a helper
+pod, and the status of both objects is set to ready. The CSI snapshot
+controller must not be installed, as it creates the same
+VolumeSnapshotContent without the details of the hostpath snapshot.
+Such a VolumeSnapshot is failed with an event.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/openebs/maya/pkg/alertlog"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+const (
+	// snapshotFinalizer is set on the VolumeSnapshot to delete the
+	// VolumeSnapshotContent along with the VolumeSnapshot.
+	snapshotFinalizer = "local.openebs.io/snapshot-protection"
+	// snapshotContentFinalizer is set on the VolumeSnapshotContent to
+	// delete the snapshot directory from the node.
+	snapshotContentFinalizer = "local.openebs.io/snapshot-cleanup"
+)
+
+// SnapshotController creates and deletes the snapshots of hostpath
+// Local PVs.
+type SnapshotController struct {
+	provisioner    *Provisioner
+	snapshotLister cache.GenericLister
+	contentLister  cache.GenericLister
+	snapshotSynced cache.InformerSynced
+	contentSynced  cache.InformerSynced
+	snapshotQueue  workqueue.RateLimitingInterface
+	contentQueue   workqueue.RateLimitingInterface
+}
+
+// NewSnapshotController returns a SnapshotController which is notified
+// of VolumeSnapshot and VolumeSnapshotContent changes via the informers
+// of the given factory.
+func NewSnapshotController(p *Provisioner, informerFactory dynamicinformer.DynamicSharedInformerFactory) *SnapshotController {
+	snapshotInformer := informerFactory.ForResource(volumeSnapshotGVR)
+	contentInformer := informerFactory.ForResource(volumeSnapshotContentGVR)
+	sc := &SnapshotController{
+		provisioner:    p,
+		snapshotLister: snapshotInformer.Lister(),
+		contentLister:  contentInformer.Lister(),
+		snapshotSynced: snapshotInformer.Informer().HasSynced,
+		contentSynced:  contentInformer.Informer().HasSynced,
+		snapshotQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "snapshot"),
+		contentQueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "snapshotcontent"),
+	}
+
+	snapshotInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			enqueueObject(sc.snapshotQueue, obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			enqueueObject(sc.snapshotQueue, newObj)
+		},
+	})
+	contentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			enqueueObject(sc.contentQueue, obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			enqueueObject(sc.contentQueue, newObj)
+		},
+	})
+	return sc
+}
+
+// isSnapshotAPIAvailable returns true if the snapshot.storage.k8s.io/v1
+// API is served by the cluster.
+func isSnapshotAPIAvailable(kubeClient clientset.Interface) bool {
+	_, err := kubeClient.Discovery().ServerResourcesForGroupVersion(volumeSnapshotGVR.GroupVersion().String())
+	return err == nil
+}
+
+// Run processes the queued VolumeSnapshots and VolumeSnapshotContents
+// till the context is cancelled.
+func (sc *SnapshotController) Run(ctx context.Context, workers int) {
+	defer utilruntime.HandleCrash()
+	defer sc.snapshotQueue.ShutDown()
+	defer sc.contentQueue.ShutDown()
+
+	klog.Info("Starting snapshot controller")
+	defer klog.Info("Shutting down snapshot controller")
+
+	if !cache.WaitForCacheSync(ctx.Done(), sc.snapshotSynced, sc.contentSynced) {
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			for processNextKey(ctx, sc.snapshotQueue, func(ctx context.Context, key string) error {
+				return sc.provisioner.trackOperation("snapshot "+key, snapshotStallTimeout, func() error {
+					return sc.syncSnapshot(ctx, key)
+				})
+			}) {
+			}
+		}, time.Second)
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			for processNextKey(ctx, sc.contentQueue, func(ctx context.Context, key string) error {
+				return sc.provisioner.trackOperation("snapshot content "+key, deleteStallTimeout, func() error {
+					return sc.syncContent(ctx, key)
+				})
+			}) {
+			}
+		}, time.Second)
+	}
+	<-ctx.Done()
+}
+
+func enqueueObject(queue workqueue.RateLimitingInterface, obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	queue.Add(key)
+}
+
+func processNextKey(ctx context.Context, queue workqueue.RateLimitingInterface, sync func(context.Context, string) error) bool {
+	key, quit := queue.Get()
+	if quit {
+		return false
+	}
+	defer queue.Done(key)
+
+	if err := sync(ctx, key.(string)); err != nil {
+		klog.Errorf("Failed to sync %v: %v", key, err)
+		queue.AddRateLimited(key)
+		return true
+	}
+	queue.Forget(key)
+	return true
+}
+
+// syncSnapshot creates the snapshot of the source PVC of the
+// VolumeSnapshot, or deletes the VolumeSnapshotContent of a
+// deleted VolumeSnapshot.
+func (sc *SnapshotController) syncSnapshot(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	obj, err := sc.snapshotLister.ByNamespace(namespace).Get(name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	snapshot := obj.(*unstructured.Unstructured).DeepCopy()
+
+	class, err := sc.getSnapshotClass(ctx, snapshot)
+	if err != nil || class == nil {
+		return err
+	}
+
+	snapshotClient := sc.provisioner.dynamicClient.Resource(volumeSnapshotGVR).Namespace(namespace)
+	if snapshot.GetDeletionTimestamp() != nil {
+		return sc.deleteSnapshot(ctx, snapshot)
+	}
+	if !hasFinalizer(snapshot, snapshotFinalizer) {
+		snapshot.SetFinalizers(append(snapshot.GetFinalizers(), snapshotFinalizer))
+		snapshot, err = snapshotClient.Update(ctx, snapshot, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to add finalizer on snapshot %v", key)
+		}
+	}
+
+	if ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); ready {
+		return nil
+	}
+	pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	if pvcName == "" {
+		klog.Infof("Skipping snapshot %v: pre-provisioned snapshots are not supported", key)
+		return nil
+	}
+
+	contentName := "snapcontent-" + string(snapshot.GetUID())
+	contentClient := sc.provisioner.dynamicClient.Resource(volumeSnapshotContentGVR)
+	content, err := contentClient.Get(ctx, contentName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		content, err = sc.createSnapshotContent(ctx, snapshot, class, pvcName, contentName)
+	}
+	if err != nil {
+		return err
+	}
+	if !isLocalSnapshotContent(content) {
+		return sc.failSnapshot(ctx, snapshot, errors.Errorf("snapshot content %v was not created by %v, "+
+			"the CSI snapshot controller must not be installed along with it", contentName, provisionerName))
+	}
+
+	if ready, _, _ := unstructured.NestedBool(content.Object, "status", "readyToUse"); !ready {
+		content, err = sc.createSnapshotData(ctx, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	restoreSize, _, _ := unstructured.NestedInt64(content.Object, "status", "restoreSize")
+	creationTime, _, _ := unstructured.NestedInt64(content.Object, "status", "creationTime")
+	status := map[string]interface{}{
+		"boundVolumeSnapshotContentName": contentName,
+		"creationTime":                   time.Unix(0, creationTime).UTC().Format(time.RFC3339),
+		"readyToUse":                     true,
+		"restoreSize":                    resource.NewQuantity(restoreSize, resource.BinarySI).String(),
+	}
+	if err := unstructured.SetNestedMap(snapshot.Object, status, "status"); err != nil {
+		return err
+	}
+	if _, err := snapshotClient.UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update status of snapshot %v", key)
+	}
+	klog.Infof("Snapshot %v is ready to use", key)
+	return nil
+}
+
+// failSnapshot sets the error of the VolumeSnapshot that cannot be
+// created, and emits an event. The VolumeSnapshot is not retried.
+func (sc *SnapshotController) failSnapshot(ctx context.Context, snapshot *unstructured.Unstructured, snapErr error) error {
+	key := snapshot.GetNamespace() + "/" + snapshot.GetName()
+	if message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); message == snapErr.Error() {
+		return nil
+	}
+	alertlog.Logger.Errorw("",
+		"eventcode", eventCodeSnapshotFailure,
+		"msg", "Failed to create snapshot of Local PV",
+		"rname", key,
+		"reason", snapErr.Error(),
+	)
+	sc.provisioner.recordEvent(snapshot, v1.EventTypeWarning, eventCodeSnapshotFailure, "Failed to create snapshot of Local PV: %v", snapErr)
+	countFailure(eventCodeSnapshotFailure, "Snapshot creation failed")
+
+	status := map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": snapErr.Error(),
+			"time":    time.Now().UTC().Format(time.RFC3339),
+		},
+		"readyToUse": false,
+	}
+	if err := unstructured.SetNestedMap(snapshot.Object, status, "status"); err != nil {
+		return err
+	}
+	if _, err := sc.provisioner.dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshot.GetNamespace()).
+		UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update status of snapshot %v", key)
+	}
+	return nil
+}
+
+// isLocalSnapshotContent returns true if the VolumeSnapshotContent has
+// the details of the hostpath snapshot set by createSnapshotContent.
+func isLocalSnapshotContent(content *unstructured.Unstructured) bool {
+	annotations := content.GetAnnotations()
+	return annotations[snapshotSourceAnnotation] != "" &&
+		annotations[snapshotPathAnnotation] != "" &&
+		annotations[snapshotNodeAnnotation] != ""
+}
+
+// getSnapshotClass returns the VolumeSnapshotClass of the VolumeSnapshot
+// if it belongs to this provisioner, else nil.
+func (sc *SnapshotController) getSnapshotClass(ctx context.Context, snapshot *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	if className == "" {
+		return nil, nil
+	}
+	class, err := sc.provisioner.dynamicClient.Resource(volumeSnapshotClassGVR).Get(ctx, className, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot class %v", className)
+	}
+	if driver, _, _ := unstructured.NestedString(class.Object, "driver"); driver != provisionerName {
+		return nil, nil
+	}
+	return class, nil
+}
+
+// createSnapshotContent creates the VolumeSnapshotContent of the
+// VolumeSnapshot. The snapshot directory and the node of the snapshot
+// are recorded as annotations.
+func (sc *SnapshotController) createSnapshotContent(ctx context.Context, snapshot, class *unstructured.Unstructured, pvcName, contentName string) (*unstructured.Unstructured, error) {
+	p := sc.provisioner
+	pvc, err := p.kubeClient.CoreV1().PersistentVolumeClaims(snapshot.GetNamespace()).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot source pvc %v/%v", snapshot.GetNamespace(), pvcName)
+	}
+	pv, err := p.getBoundHostPathVolume(ctx, pvc)
+	if err != nil {
+		return nil, err
+	}
+	if pv.Annotations[absolutePathAnnotation] == "true" {
+		return nil, errors.Errorf("snapshot is not supported for volume %v with absolute path", pv.Name)
+	}
+
+	parameters, _, _ := unstructured.NestedStringMap(class.Object, "parameters")
+	snapshotRoot, err := getSnapshotRoot(pv, parameters)
+	if err != nil {
+		return nil, err
+	}
+	mode := parameters[KeySnapshotMode]
+	if mode == "" {
+		mode = sourceModeCopy
+	}
+	if mode != sourceModeCopy && mode != sourceModeTar {
+		return nil, errors.Errorf("invalid %v %q in snapshot class %v", KeySnapshotMode, mode, class.GetName())
+	}
+
+	nodeAffinityLabels := persistentvolume.NewForAPIObject(pv).GetAffinitedNodeLabels()
+	if len(nodeAffinityLabels) == 0 {
+		return nil, errors.Errorf("cannot find affinited node details of volume %v", pv.Name)
+	}
+	nodeLabels, err := json.Marshal(nodeAffinityLabels)
+	if err != nil {
+		return nil, err
+	}
+	deletionPolicy, _, _ := unstructured.NestedString(class.Object, "deletionPolicy")
+
+	content := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": volumeSnapshotContentGVR.GroupVersion().String(),
+		"kind":       "VolumeSnapshotContent",
+		"metadata": map[string]interface{}{
+			"name": contentName,
+		},
+		"spec": map[string]interface{}{
+			"deletionPolicy": deletionPolicy,
+			"driver":         provisionerName,
+			"source": map[string]interface{}{
+				"volumeHandle": pv.Name,
+			},
+			"volumeSnapshotClassName": class.GetName(),
+			"volumeSnapshotRef": map[string]interface{}{
+				"apiVersion": volumeSnapshotGVR.GroupVersion().String(),
+				"kind":       SnapshotKind,
+				"name":       snapshot.GetName(),
+				"namespace":  snapshot.GetNamespace(),
+				"uid":        string(snapshot.GetUID()),
+			},
+		},
+	}}
+	content.SetAnnotations(map[string]string{
+		snapshotPathAnnotation:   filepath.Join(snapshotRoot, contentName),
+		snapshotModeAnnotation:   mode,
+		snapshotNodeAnnotation:   string(nodeLabels),
+		snapshotSourceAnnotation: pv.Name,
+	})
+	content.SetFinalizers([]string{snapshotContentFinalizer})
+
+	content, err = p.dynamicClient.Resource(volumeSnapshotContentGVR).Create(ctx, content, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create snapshot content %v", contentName)
+	}
+	return content, nil
+}
+
+// createSnapshotData copies the data of the source volume into the
+// snapshot directory and marks the VolumeSnapshotContent ready.
+func (sc *SnapshotController) createSnapshotData(ctx context.Context, content *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	p := sc.provisioner
+	annotations := content.GetAnnotations()
+	pv, err := p.kubeClient.CoreV1().PersistentVolumes().Get(ctx, annotations[snapshotSourceAnnotation], metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot source volume %v", annotations[snapshotSourceAnnotation])
+	}
+
+	snapshotPath := annotations[snapshotPathAnnotation]
+	err = p.takeSnapshot(ctx, pv, filepath.Dir(snapshotPath), content.GetName(), annotations[snapshotModeAnnotation])
+	if err != nil {
+		return nil, err
+	}
+
+	restoreSize := pv.Spec.Capacity[v1.ResourceStorage]
+	status := map[string]interface{}{
+		"creationTime":   time.Now().UnixNano(),
+		"readyToUse":     true,
+		"restoreSize":    restoreSize.Value(),
+		"snapshotHandle": content.GetName(),
+	}
+	if err := unstructured.SetNestedField(content.Object, status, "status"); err != nil {
+		return nil, err
+	}
+	content, err = p.dynamicClient.Resource(volumeSnapshotContentGVR).UpdateStatus(ctx, content, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update status of snapshot content %v", content.GetName())
+	}
+	return content, nil
+}
+
+// deleteSnapshot deletes the VolumeSnapshotContent of the deleted
+// VolumeSnapshot if its deletion policy is Delete, and then removes
+// the finalizer from the VolumeSnapshot.
+func (sc *SnapshotController) deleteSnapshot(ctx context.Context, snapshot *unstructured.Unstructured) error {
+	if !hasFinalizer(snapshot, snapshotFinalizer) {
+		return nil
+	}
+
+	contentClient := sc.provisioner.dynamicClient.Resource(volumeSnapshotContentGVR)
+	contentName := "snapcontent-" + string(snapshot.GetUID())
+	content, err := contentClient.Get(ctx, contentName, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get snapshot content %v", contentName)
+	}
+	if err == nil && content.GetDeletionTimestamp() == nil {
+		deletionPolicy, _, _ := unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+		if deletionPolicy == "Delete" {
+			err = contentClient.Delete(ctx, contentName, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to delete snapshot content %v", contentName)
+			}
+		}
+	}
+
+	removeFinalizer(snapshot, snapshotFinalizer)
+	_, err = sc.provisioner.dynamicClient.Resource(volumeSnapshotGVR).Namespace(snapshot.GetNamespace()).
+		Update(ctx, snapshot, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove finalizer from snapshot %v/%v", snapshot.GetNamespace(), snapshot.GetName())
+	}
+	return nil
+}
+
+// syncContent deletes the snapshot directory of a deleted
+// VolumeSnapshotContent with the Delete deletion policy.
+func (sc *SnapshotController) syncContent(ctx context.Context, key string) error {
+	obj, err := sc.contentLister.Get(key)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	content := obj.(*unstructured.Unstructured).DeepCopy()
+	if content.GetDeletionTimestamp() == nil || !hasFinalizer(content, snapshotContentFinalizer) {
+		return nil
+	}
+
+	deletionPolicy, _, _ := unstructured.NestedString(content.Object, "spec", "deletionPolicy")
+	if deletionPolicy == "Delete" {
+		if err := sc.provisioner.deleteSnapshot(ctx, content); err != nil {
+			return err
+		}
+	}
+
+	removeFinalizer(content, snapshotContentFinalizer)
+	_, err = sc.provisioner.dynamicClient.Resource(volumeSnapshotContentGVR).Update(ctx, content, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove finalizer from snapshot content %v", key)
+	}
+	return nil
+}
+
+func hasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(obj metav1.Object, finalizer string) {
+	finalizers := []string{}
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
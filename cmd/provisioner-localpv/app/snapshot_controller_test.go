@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSyncSnapshotContent(t *testing.T) {
+	fakeSnapshot := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "snapshot.storage.k8s.io/v1",
+			"kind":       "VolumeSnapshot",
+			"metadata": map[string]interface{}{
+				"name":       "snap",
+				"namespace":  "default",
+				"uid":        "1234",
+				"finalizers": []interface{}{snapshotFinalizer},
+			},
+			"spec": map[string]interface{}{
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": "source-pvc"},
+				"volumeSnapshotClassName": "openebs-hostpath-snapshot",
+			},
+		}}
+	}
+	fakeContent := func(annotations map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "snapshot.storage.k8s.io/v1",
+			"kind":       "VolumeSnapshotContent",
+			"metadata": map[string]interface{}{
+				"name":        "snapcontent-1234",
+				"annotations": annotations,
+			},
+			"spec":   map[string]interface{}{"driver": provisionerName},
+			"status": map[string]interface{}{"readyToUse": true, "restoreSize": int64(1 << 30)},
+		}}
+	}
+	class := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshotClass",
+		"metadata":   map[string]interface{}{"name": "openebs-hostpath-snapshot"},
+		"driver":     provisionerName,
+	}}
+
+	testCases := map[string]struct {
+		content      *unstructured.Unstructured
+		expectReady  bool
+		expectError  string
+		expectEvents int
+	}{
+		"content of the provisioner": {
+			content: fakeContent(map[string]interface{}{
+				snapshotSourceAnnotation: "source-pv",
+				snapshotPathAnnotation:   "/var/openebs/local/.snapshots/snapcontent-1234",
+				snapshotModeAnnotation:   sourceModeCopy,
+				snapshotNodeAnnotation:   `{"kubernetes.io/hostname":"node-1"}`,
+			}),
+			expectReady: true,
+		},
+		"content of the CSI snapshot controller": {
+			content:      fakeContent(nil),
+			expectError:  "was not created by " + provisionerName,
+			expectEvents: 1,
+		},
+	}
+	for k, v := range testCases {
+		v := v
+		t.Run(k, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			listKinds := map[schema.GroupVersionResource]string{
+				volumeSnapshotGVR:        "VolumeSnapshotList",
+				volumeSnapshotContentGVR: "VolumeSnapshotContentList",
+				volumeSnapshotClassGVR:   "VolumeSnapshotClassList",
+			}
+			recorder := record.NewFakeRecorder(10)
+			p := &Provisioner{
+				dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, fakeSnapshot(), v.content, class),
+				recorder:      recorder,
+			}
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			sc := &SnapshotController{
+				provisioner:    p,
+				snapshotLister: cache.NewGenericLister(indexer, volumeSnapshotGVR.GroupResource()),
+			}
+
+			// The second sync must not emit the event again.
+			for i := 0; i < 2; i++ {
+				snapshot, err := p.dynamicClient.Resource(volumeSnapshotGVR).Namespace("default").Get(context.TODO(), "snap", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("expected snapshot to be found, but got %v", err)
+				}
+				if err := indexer.Update(snapshot); err != nil {
+					t.Fatal(err)
+				}
+				if err := sc.syncSnapshot(context.TODO(), "default/snap"); err != nil {
+					t.Fatalf("expected error to be nil, but got %v", err)
+				}
+			}
+
+			snapshot, err := p.dynamicClient.Resource(volumeSnapshotGVR).Namespace("default").Get(context.TODO(), "snap", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected snapshot to be found, but got %v", err)
+			}
+			ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+			if ready != v.expectReady {
+				t.Errorf("expected readyToUse %v, but got %v", v.expectReady, ready)
+			}
+			message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+			if (v.expectError == "") != (message == "") || !strings.Contains(message, v.expectError) {
+				t.Errorf("expected error message with %q, but got %q", v.expectError, message)
+			}
+			if len(recorder.Events) != v.expectEvents {
+				t.Errorf("expected %v events, but got %v", v.expectEvents, len(recorder.Events))
+			}
+		})
+	}
+}
@@ -19,7 +19,10 @@ package app
 import (
 	"context"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	analytics "github.com/openebs/google-analytics-4/usage"
 	menv "github.com/openebs/maya/pkg/env/v1alpha1"
@@ -28,6 +31,12 @@ import (
 	"github.com/openebs/maya/pkg/version"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
 	pvController "sigs.k8s.io/sig-storage-lib-external-provisioner/v9/controller"
 )
@@ -39,6 +48,9 @@ var (
 	// localpv provisioner
 	LeaderElectionKey = "LEADER_ELECTION_ENABLED"
 	usage             = cmdName
+	// resyncPeriod is the resync period of the informers used by
+	// the controllers started along with the provisioner.
+	resyncPeriod = 15 * time.Minute
 )
 
 // StartProvisioner will start a new dynamic Host Path PV provisioner
@@ -54,6 +66,7 @@ func StartProvisioner() (*cobra.Command, error) {
 			util.CheckErr(Start(cmd), util.Fatal)
 		},
 	}
+	cmd.AddCommand(newRestoreTrashCommand())
 
 	return cmd, nil
 }
@@ -73,18 +86,29 @@ func Start(cmd *cobra.Command) error {
 		return errors.Wrap(err, "unable to get k8s client")
 	}
 
+	dynamicClient, err := mKube.New().Dynamic()
+	if err != nil {
+		return errors.Wrap(err, "unable to get k8s dynamic client")
+	}
+
 	err = performPreupgradeTasks(context.TODO(), kubeClient)
 	if err != nil {
 		return errors.Wrap(err, "failure in preupgrade tasks")
 	}
 
 	//Create a context to receive shutdown signal to help
-	// with graceful exit of the provisioner.
-	ctx := context.TODO()
+	// with graceful exit of the provisioner. The controllers run
+	// with runCtx, which is cancelled once the volumes being
+	// provisioned or deleted are done, or the shutdown timeout
+	// has passed.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
 
 	//Create an instance of ProvisionerHandler to handle PV
 	// create and delete events.
-	provisioner, err := NewProvisioner(kubeClient)
+	provisioner, err := NewProvisioner(kubeClient, dynamicClient)
 	if err != nil {
 		return err
 	}
@@ -92,27 +116,164 @@ func Start(cmd *cobra.Command) error {
 	//Create an instance of the Dynamic Provisioner Controller
 	// that has the reconciliation loops for PVC create and delete
 	// events and invokes the Provisioner Handler.
+	leaderElection := isLeaderElectionEnabled()
+	// Leader election is run by the provisioner, instead of the
+	// controller, so that the lease is released on shutdown.
 	pc := pvController.NewProvisionController(
 		kubeClient,
 		provisionerName,
 		provisioner,
-		pvController.LeaderElection(isLeaderElectionEnabled()),
+		pvController.LeaderElection(false),
 	)
 
+	//Serve the metrics, and the liveness and readiness of the
+	// provisioner, unless their address is set to an empty value.
+	health := newHealthChecker(kubeClient, leaderElection, pc.HasRun, provisioner.operations)
+	for address, mux := range newServeMuxes(getMetricsAddress(), getHealthProbeAddress(), health) {
+		go runHTTPServer(runCtx, address, mux)
+	}
+
+	//The controllers below run along with the provision controller,
+	// i.e. only on the leader if leader election is enabled, so
+	// that the replicas do not act on the same volumes.
+	var controllers []func(context.Context)
+
+	//Delete the helper pods left behind by a previous run of the
+	// provisioner. It runs only on the leader, as the helper jobs
+	// of the volumes in progress are waited on by the leader.
+	controllers = append(controllers, func(ctx context.Context) {
+		if err := provisioner.GarbageCollectHelperPods(ctx); err != nil {
+			klog.Errorf("Failed to garbage collect helper pods: %v", err)
+		}
+	})
+
+	//Create an instance of the Resize Controller to expand the
+	// hostpath volumes, as the external provisioner library does
+	// not handle volume expansion.
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, resyncPeriod)
+	resizeController := NewResizeController(
+		provisioner,
+		informerFactory.Core().V1().PersistentVolumeClaims(),
+	)
+	controllers = append(controllers, func(ctx context.Context) {
+		informerFactory.Start(ctx.Done())
+		resizeController.Run(ctx, 1)
+	})
+
+	//Create an instance of the Snapshot Controller to snapshot the
+	// hostpath volumes, if the VolumeSnapshot CRDs are installed.
+	if isSnapshotAPIAvailable(kubeClient) {
+		dynamicInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, resyncPeriod)
+		snapshotController := NewSnapshotController(provisioner, dynamicInformerFactory)
+		controllers = append(controllers, func(ctx context.Context) {
+			dynamicInformerFactory.Start(ctx.Done())
+			snapshotController.Run(ctx, 1)
+		})
+	} else {
+		klog.Info("VolumeSnapshot API is not available, snapshot controller is disabled")
+	}
+
+	//Create an instance of the Capacity Controller to publish the
+	// capacity available to the hostpath StorageClasses on each node.
+	if isCapacityTrackingEnabled() {
+		capacityController := NewCapacityController(provisioner, getCapacityPollInterval())
+		controllers = append(controllers, capacityController.Run)
+	}
+
+	//Create an instance of the Trash Controller to purge the
+	// trashed hostpath volumes.
+	trashController := NewTrashController(provisioner, getTrashPurgeInterval())
+	controllers = append(controllers, trashController.Run)
+
 	if menv.Truthy(menv.OpenEBSEnableAnalytics) {
 		analytics.RegisterVersionGetter(version.GetVersionDetails)
 		analytics.New().CommonBuild(DefaultCASType).InstallBuilder(true).Send()
 		go analytics.PingCheck(DefaultCASType, Ping)
 	}
 
+	//Stop accepting new volumes on a shutdown signal, and wait for
+	// the volumes being provisioned or deleted and their helper pods.
+	go func() {
+		<-ctx.Done()
+		shutdownTimeout := getShutdownTimeout()
+		klog.Infof("Shutting down, waiting up to %v for the volumes in progress", shutdownTimeout)
+		if pending := provisioner.operations.drain(shutdownTimeout); len(pending) > 0 {
+			klog.Warningf("Stopping with operations in progress: %v", pending)
+		}
+		cancelRun()
+	}()
+
+	run := func(ctx context.Context) {
+		leader.Set(1)
+		defer leader.Set(0)
+		for _, controller := range controllers {
+			go controller(ctx)
+		}
+		pc.Run(ctx)
+	}
+
 	klog.V(4).Info("Provisioner started")
 	//Run the provisioner till a shutdown signal is received.
-	pc.Run(ctx)
+	if leaderElection {
+		if err := runWithLeaderElection(runCtx, kubeClient, provisioner, run); err != nil {
+			return err
+		}
+	} else {
+		go run(runCtx)
+		<-runCtx.Done()
+	}
 	klog.V(4).Info("Provisioner stopped")
 
 	return nil
 }
 
+// runWithLeaderElection runs the provision controller, and the other
+// controllers, while the provisioner holds the leader lease, until the
+// context is cancelled.
+// The lease is the one used by the provision controller of the
+// sig-storage-lib-external-provisioner, so that the provisioner can be
+// upgraded without two leaders. The lease is released on shutdown, so
+// that another replica can take over without waiting for it to expire.
+func runWithLeaderElection(ctx context.Context, kubeClient clientset.Interface, p *Provisioner, run func(context.Context)) error {
+	id, err := os.Hostname()
+	if err != nil {
+		return errors.Wrap(err, "failed to get hostname")
+	}
+	id = id + "_" + string(uuid.NewUUID())
+
+	lock, err := resourcelock.New(resourcelock.EndpointsLeasesResourceLock,
+		p.namespace,
+		strings.Replace(provisionerName, "/", "-", -1),
+		kubeClient.CoreV1(),
+		kubeClient.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      id,
+			EventRecorder: p.recorder,
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to create leader election lock")
+	}
+
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   pvController.DefaultLeaseDuration,
+		RenewDeadline:   pvController.DefaultRenewDeadline,
+		RetryPeriod:     pvController.DefaultRetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: run,
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					klog.Info("Released leader lease")
+					return
+				}
+				klog.Fatalf("Lost leader lease")
+			},
+		},
+	})
+	return nil
+}
+
 // isLeaderElectionEnabled returns true/false based on the ENV
 // LEADER_ELECTION_ENABLED set via provisioner deployment.
 // Defaults to true, means leaderElection enabled by default.
@@ -0,0 +1,159 @@
+/*
+Copyright 2026 The OpenEBS Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+This file contains the controller used to purge the trashed hostpath
+volumes.
+
+At every purge interval, the trash records are listed. The trashed
+directories whose retention period is over are removed. Then, for every
+trash directory with a minAvailable, a helper pod measures the space
+available to its filesystem, and the oldest trashed directories are
+removed till the available space is at least minAvailable.
+*/
+
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/openebs/dynamic-localpv-provisioner/pkg/kubernetes/api/core/v1/persistentvolume"
+)
+
+// TrashController purges the trashed hostpath volumes.
+type TrashController struct {
+	provisioner *Provisioner
+	interval    time.Duration
+}
+
+// NewTrashController returns a TrashController which checks the
+// trashed volumes at the given interval.
+func NewTrashController(p *Provisioner, interval time.Duration) *TrashController {
+	return &TrashController{
+		provisioner: p,
+		interval:    interval,
+	}
+}
+
+// Run purges the trashed volumes at every interval till the context is
+// cancelled.
+func (tc *TrashController) Run(ctx context.Context) {
+	klog.Infof("Starting trash controller with purge interval %v", tc.interval)
+	defer klog.Info("Shutting down trash controller")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := tc.sync(ctx); err != nil {
+			klog.Errorf("Failed to purge trash: %v", err)
+		}
+	}, tc.interval)
+}
+
+// sync purges the expired trashed volumes, and the oldest trashed
+// volumes of the trash directories which are below their minAvailable.
+func (tc *TrashController) sync(ctx context.Context) error {
+	p := tc.provisioner
+	configMapList, err := p.kubeClient.CoreV1().ConfigMaps(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: trashLabel + "=true",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list trash records")
+	}
+
+	// trashes groups the records of the volumes which are not expired,
+	// by the trash directory on a node
+	trashes := map[string][]*trashRecord{}
+	now := time.Now()
+	for i := range configMapList.Items {
+		record, err := newTrashRecord(&configMapList.Items[i])
+		if err != nil {
+			klog.Errorf("Skipping trash record: %v", err)
+			continue
+		}
+		if record.isExpired(now) {
+			if err := tc.purgeTrash(ctx, record); err != nil {
+				klog.Errorf("Failed to purge expired trash: %v", err)
+			}
+			continue
+		}
+		if record.minAvailable != "" {
+			key := trashKey(record)
+			trashes[key] = append(trashes[key], record)
+		}
+	}
+
+	for _, records := range trashes {
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].trashedAt.Before(records[j].trashedAt)
+		})
+		if err := tc.purgeBelowMinAvailable(ctx, records); err != nil {
+			klog.Errorf("Failed to purge trash of %v: %v", records[0].root, err)
+		}
+	}
+	return ctx.Err()
+}
+
+// purgeBelowMinAvailable purges the records of a trash directory,
+// oldest first, while the available space of its filesystem is below
+// the minAvailable of the record.
+func (tc *TrashController) purgeBelowMinAvailable(ctx context.Context, records []*trashRecord) error {
+	p := tc.provisioner
+	nodeAffinityLabels := persistentvolume.NewForAPIObject(records[0].pv).GetAffinitedNodeLabels()
+	node, err := p.GetNodeObjectFromLabels(nodeAffinityLabels)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		var used, available int64
+		err := p.trackOperation("capacity of "+record.root+" on "+node.Name, helperStallTimeout, func() (err error) {
+			used, available, err = p.getFilesystemUsage(ctx, node, shortHash(node.Name+":"+record.root), record.root)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !isBelowMinAvailable(record.minAvailable, used, available) {
+			return nil
+		}
+		if err := tc.purgeTrash(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeTrash purges the trashed volume of the record, tracked as an
+// operation of the provisioner.
+func (tc *TrashController) purgeTrash(ctx context.Context, record *trashRecord) error {
+	return tc.provisioner.trackOperation("purge "+record.entry, deleteStallTimeout, func() error {
+		return tc.provisioner.purgeTrash(ctx, record)
+	})
+}
+
+// trashKey returns the key of the trash directory of the record, made
+// of the node affinity labels and the root path of the volume.
+func trashKey(record *trashRecord) string {
+	nodeAffinityLabels := persistentvolume.NewForAPIObject(record.pv).GetAffinitedNodeLabels()
+	return labels.Set(nodeAffinityLabels).String() + ":" + record.root
+}
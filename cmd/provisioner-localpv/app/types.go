@@ -22,7 +22,9 @@ import (
 
 	mconfig "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
@@ -34,14 +36,28 @@ const (
 // Provisioner struct has the configuration and utilities required
 // across the different work-flows.
 type Provisioner struct {
-	kubeClient  *clientset.Clientset
+	kubeClient clientset.Interface
+	// dynamicClient is used to access the VolumeSnapshot objects
+	dynamicClient dynamic.Interface
+	// ndm is used to access the BlockDevices and BlockDeviceClaims
+	ndm         ndmClient
 	namespace   string
 	helperImage string
+	// helperNamespace is the namespace of the helper jobs
+	helperNamespace string
 	// defaultConfig is the default configurations
 	// provided from ENV or Code
 	defaultConfig []mconfig.Config
 	// getVolumeConfig is a reference to a function
 	getVolumeConfig GetVolumeConfigFn
+	// basePathRoundRobin is the state of the RoundRobin BasePathPolicy
+	basePathRoundRobin *basePathRoundRobin
+	// recorder emits the events of the volumes
+	recorder record.EventRecorder
+	// operations tracks the Provision and Delete calls in progress
+	operations *operationTracker
+	// quotaProjects allocates the quota project IDs of the volumes
+	quotaProjects *quotaProjectAllocator
 }
 
 // VolumeConfig struct contains the merged configuration of the PVC
@@ -60,12 +76,13 @@ type Provisioner struct {
 //	  },
 //	}
 type VolumeConfig struct {
-	pvName     string
-	pvcName    string
-	scName     string
-	options    map[string]interface{}
-	configData map[string]interface{}
-	configList map[string]interface{}
+	pvName       string
+	pvcName      string
+	pvcNamespace string
+	scName       string
+	options      map[string]interface{}
+	configData   map[string]interface{}
+	configList   map[string]interface{}
 }
 
 // GetVolumeConfigFn allows to plugin a custom function
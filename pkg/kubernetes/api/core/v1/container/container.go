@@ -313,6 +313,22 @@ func (b *Builder) WithPrivilegedSecurityContext(privileged *bool) *Builder {
 	return b
 }
 
+// WithSecurityContext sets securitycontext of the container
+func (b *Builder) WithSecurityContext(securityContext *corev1.SecurityContext) *Builder {
+	if securityContext == nil {
+		b.errors = append(
+			b.errors,
+			errors.New(
+				"failed to build container object: missing securitycontext",
+			),
+		)
+		return b
+	}
+
+	b.con.SecurityContext = securityContext.DeepCopy()
+	return b
+}
+
 // WithResources sets resources of the container
 func (b *Builder) WithResources(
 	resources *corev1.ResourceRequirements,
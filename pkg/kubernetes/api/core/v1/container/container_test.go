@@ -279,6 +279,41 @@ func TestBuilderWithPrivilegedSecurityContext(t *testing.T) {
 	}
 }
 
+func TestBuilderWithSecurityContext(t *testing.T) {
+	tests := map[string]struct {
+		secCont   *corev1.SecurityContext
+		builder   *Builder
+		expectErr bool
+	}{
+		"Test Builder with securityContext": {
+			secCont: &corev1.SecurityContext{},
+			builder: &Builder{con: &container{
+				corev1.Container{},
+			}},
+			expectErr: false,
+		},
+		"Test Builder without securityContext": {
+			secCont: nil,
+			builder: &Builder{con: &container{
+				corev1.Container{},
+			}},
+			expectErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name, mock := name, mock
+		t.Run(name, func(t *testing.T) {
+			b := mock.builder.WithSecurityContext(mock.secCont)
+			if mock.expectErr && len(b.errors) == 0 {
+				t.Fatalf("Test %q failed: expected error not to be nil", name)
+			}
+			if !mock.expectErr && len(b.errors) > 0 {
+				t.Fatalf("Test %q failed: expected error to be nil", name)
+			}
+		})
+	}
+}
+
 func TestBuilderWithEnvsNew(t *testing.T) {
 	tests := map[string]struct {
 		envList   []corev1.EnvVar